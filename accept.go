@@ -0,0 +1,102 @@
+package mutable
+
+import (
+	"reflect"
+)
+
+// AcceptField moves a current value of a field into an original state, so the field is not reported as changed anymore.
+// Field is looked up by its path (see SetValue), other changes are kept as is
+func (m *Mutable) AcceptField(fieldName string) error {
+	if m.target == nil {
+		return errNoTarget
+	}
+	target := reflect.ValueOf(m.target).Elem()
+	tokens, ok := m.paths.splitExisting(target, fieldName)
+	if !ok {
+		if tokens, ok = m.paths.splitExisting(m.original(), fieldName); !ok {
+			return errCannotFind(fieldName)
+		}
+	}
+	original := deepCopy(reflect.ValueOf(m.originalState))
+	if err := acceptPath(target, original, tokens); err != nil {
+		return errCannotSetPath(fieldName, err)
+	}
+	m.originalState = original.Interface()
+	// Invalidate analyzed changes
+	m.analyzed = false
+	return nil
+}
+
+// AcceptChanges moves current values of given changed fields into an original state, so they are not reported
+// as changed anymore. Nested fields of deep analyzed structs and map elements are accepted one by one,
+// changed slices are accepted entirely. Changes are accepted atomically: if any field can't be accepted,
+// an original state is left untouched
+func (m *Mutable) AcceptChanges(changedFields ChangedFields) error {
+	if m.target == nil {
+		return errNoTarget
+	}
+	original := deepCopy(reflect.ValueOf(m.originalState))
+	if err := acceptChanges(reflect.ValueOf(m.target).Elem(), original, nil, changedFields); err != nil {
+		return err
+	}
+	m.originalState = original.Interface()
+	// Invalidate analyzed changes
+	m.analyzed = false
+	return nil
+}
+
+// acceptChanges moves current values of changed fields with a path prefix within target into original
+func acceptChanges(target, original reflect.Value, prefix []string, changedFields ChangedFields) error {
+	for _, field := range changedFields {
+		name := field.JSONName
+		if len(name) == 0 {
+			name = field.Name
+		}
+		path := append(prefix[:len(prefix):len(prefix)], name)
+		var err error
+		switch {
+		case field.kind == reflect.Map && len(field.NestedFields) > 0:
+			for key, elem := range field.NestedFields {
+				elemPath := append(path[:len(path):len(path)], key)
+				if elem.Status == Changed && len(elem.NestedFields) > 0 {
+					err = acceptChanges(target, original, elemPath, elem.NestedFields)
+				} else {
+					err = acceptPath(target, original, elemPath)
+				}
+				if err != nil {
+					break
+				}
+			}
+		case field.kind != reflect.Slice && len(field.NestedFields) > 0:
+			err = acceptChanges(target, original, path, field.NestedFields)
+		default:
+			err = acceptPath(target, original, path)
+		}
+		if err != nil {
+			return errCannotSetPath(formatPointer(path), err)
+		}
+	}
+	return nil
+}
+
+// acceptPath sets a deep copy of a target value with a given path to original.
+// If a target map has no element with a given key, it's deleted from original
+func acceptPath(target, original reflect.Value, path []string) error {
+	value, err := lookupPath(target, path)
+	switch {
+	case err == errPathNotFound:
+		// Element may be removed from a map
+		return walkPath(original, path, func(container reflect.Value, token string) error {
+			if container.Kind() != reflect.Map {
+				return errPathNotFound
+			}
+			return clearPathValue(container, token)
+		})
+	case err != nil:
+		return err
+	}
+	return walkPath(original, path, func(container reflect.Value, token string) error {
+		// Map elements may be added, slices elements are replaced
+		return setPathValue(copiedValue(value), container.Kind() == reflect.Map)(container, token)
+	})
+}
@@ -0,0 +1,92 @@
+package mutable
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMutable_AcceptField(t *testing.T) {
+	var tst = &TestF{
+		FieldA: "one",
+		FieldB: TestB{FieldA: "a", FieldB: []int{1}},
+		FieldC: &TestC{FieldA: "c"},
+	}
+	tst.ResetMutableState(tst)
+	tst.FieldA = "two"
+	tst.FieldB.FieldA = "b"
+	tst.FieldB.FieldB[0] = 2
+	tst.FieldC.FieldA = "d"
+	assert.Len(t, tst.AnalyzeChanges(), 3)
+
+	assert.NoError(t, tst.AcceptField("field/a"))
+	assert.NoError(t, tst.AcceptField("field/field_b"))
+	changes := tst.AnalyzeChanges()
+	assert.Len(t, changes, 2)
+	assert.Len(t, changes["FieldB"].NestedFields, 1)
+	assert.Contains(t, changes["FieldB"].NestedFields, "FieldA")
+
+	// Accepted values are copies
+	tst.FieldB.FieldB[0] = 3
+	assert.Len(t, tst.AnalyzeChangesForce()["FieldB"].NestedFields, 2)
+
+	// Accepted values are kept on revert
+	assert.NoError(t, tst.Revert())
+	assert.Equal(t, "two", tst.FieldA)
+	assert.Equal(t, "a", tst.FieldB.FieldA)
+	assert.Equal(t, []int{2}, tst.FieldB.FieldB)
+
+	assert.Error(t, tst.AcceptField("unknown"))
+	assert.Error(t, (&TestF{}).AcceptField("field/a"))
+}
+
+func TestMutable_AcceptChanges(t *testing.T) {
+	var tst = &TestE{
+		FieldA: "one",
+		FieldD: []*TestD{{ID: 1}, {ID: 2}},
+		FieldF: map[string]string{"a": "1", "b": "2"},
+		FieldG: map[string]*TestC{"x": {FieldA: "x"}},
+	}
+	tst.ResetMutableState(tst)
+	tst.FieldA = "two"
+	tst.FieldC.FieldA = "c"
+	tst.FieldC.FieldB = []int{1}
+	tst.FieldD = tst.FieldD[1:]
+	tst.FieldF["a"] = "3"
+	delete(tst.FieldF, "b")
+	tst.FieldF["c"] = "4"
+	tst.FieldG["x"].FieldA = "y"
+	tst.FieldG["x"].FieldB = []int{1}
+	changes := tst.AnalyzeChanges()
+	assert.Len(t, changes, 5)
+
+	// Accept a part of changes
+	accepted := ChangedFields{
+		"FieldA": changes["FieldA"],
+		"FieldC": &ChangedField{
+			Name:         "FieldC",
+			JSONName:     "field/c",
+			NestedFields: ChangedFields{"FieldA": changes["FieldC"].NestedFields["FieldA"]},
+		},
+		"FieldD": changes["FieldD"],
+		"FieldF": changes["FieldF"],
+	}
+	assert.NoError(t, tst.AcceptChanges(accepted))
+	changes = tst.AnalyzeChanges()
+	assert.Len(t, changes, 2)
+	assert.Len(t, changes["FieldC"].NestedFields, 1)
+	assert.Contains(t, changes["FieldC"].NestedFields, "FieldB")
+	assert.Contains(t, changes, "FieldG")
+
+	assert.NoError(t, tst.AcceptChanges(changes))
+	assert.Empty(t, tst.AnalyzeChanges())
+
+	// Failed accept leaves an original state untouched
+	tst.FieldA = "three"
+	assert.Error(t, tst.AcceptChanges(ChangedFields{
+		"FieldA":  &ChangedField{Name: "FieldA", JSONName: "field_a"},
+		"Unknown": &ChangedField{Name: "Unknown"},
+	}))
+	assert.Len(t, tst.AnalyzeChangesForce(), 1)
+	assert.Error(t, (&TestE{}).AcceptChanges(ChangedFields{}))
+}
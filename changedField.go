@@ -2,6 +2,7 @@ package mutable
 
 import (
 	"encoding/json"
+	"reflect"
 
 	"github.com/go-ext/logger"
 )
@@ -12,9 +13,23 @@ type ChangedFields map[string]*ChangedField
 // ChangedField contains struct's fields changes data
 type ChangedField struct {
 	Name         string        `json:"-"`                       // Field name
+	JSONName     string        `json:"-"`                       // JSON name of a struct field (empty for collection elements)
 	OldValue     interface{}   `json:"old_value"`               // Old value
 	NewValue     interface{}   `json:"new_value"`               // New value
-	NestedFields ChangedFields `json:"nested_fields,omitempty"` // Nested fields changes data (if a field is a struct and has "mutable:deep" tag value)
+	NestedFields ChangedFields `json:"nested_fields,omitempty"` // Nested fields changes data (deep analyzed structs and collection elements)
+	Status       Status        `json:"status,omitempty"`        // Status of a collection element (Added, Removed, Changed or Moved)
+	OldIndex     int           `json:"-"`                       // Index of a slice element within an original slice (-1 for added elements)
+	NewIndex     int           `json:"-"`                       // Index of a slice element within a current slice (-1 for removed elements)
+	kind         reflect.Kind  // Kind of a collection (slice or map) for element-level changes within NestedFields
+	current      interface{}   // Current value of a collection for element-level changes within NestedFields
+	originalNil  bool          // Original collection is nil
+	currentNil   bool          // Current collection is nil
+}
+
+// add adds a changedField with a given name to c
+func (c ChangedFields) add(name string, changedField *ChangedField) {
+	changedField.Name = name
+	c[name] = changedField
 }
 
 // Contains reports whether a field with fieldName exists within c
@@ -0,0 +1,61 @@
+package mutable
+
+import (
+	"reflect"
+)
+
+// Checkpoint stores a snapshot of a current state of a target object with a given name.
+// An existing checkpoint with the same name is replaced
+func (m *Mutable) Checkpoint(name string) error {
+	if m.target == nil {
+		return errNoTarget
+	}
+	if m.checkpoints == nil {
+		m.checkpoints = map[string]interface{}{}
+	}
+	m.checkpoints[name] = deepCopy(reflect.ValueOf(m.target).Elem()).Interface()
+	return nil
+}
+
+// RemoveCheckpoint removes a checkpoint with a given name
+func (m *Mutable) RemoveCheckpoint(name string) {
+	delete(m.checkpoints, name)
+}
+
+// ChangesSince returns changes of a target object made since a checkpoint with a given name.
+// Mutable statuses and cached changes of a target object are not updated
+func (m *Mutable) ChangesSince(name string) (ChangedFields, error) {
+	checkpoint, ok := m.checkpoints[name]
+	if !ok {
+		return nil, errNoCheckpoint(name)
+	}
+	return diffValues(reflect.ValueOf(m.target).Elem(), reflect.ValueOf(checkpoint)), nil
+}
+
+// ChangesBetween returns changes of a target object made between checkpoints with given names
+func (m *Mutable) ChangesBetween(from, to string) (ChangedFields, error) {
+	fromState, ok := m.checkpoints[from]
+	if !ok {
+		return nil, errNoCheckpoint(from)
+	}
+	toState, ok := m.checkpoints[to]
+	if !ok {
+		return nil, errNoCheckpoint(to)
+	}
+	return diffValues(reflect.ValueOf(toState), reflect.ValueOf(fromState)), nil
+}
+
+// RestoreCheckpoint restores a target object to a state of a checkpoint with a given name.
+// Nested mutable objects are reset with restored values
+func (m *Mutable) RestoreCheckpoint(name string) error {
+	checkpoint, ok := m.checkpoints[name]
+	if !ok {
+		return errNoCheckpoint(name)
+	}
+	if err := restoreState(reflect.ValueOf(m.target).Elem(), reflect.ValueOf(checkpoint)); err != nil {
+		return err
+	}
+	// Invalidate analyzed changes
+	m.analyzed = false
+	return nil
+}
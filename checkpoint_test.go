@@ -0,0 +1,71 @@
+package mutable
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMutable_Checkpoint(t *testing.T) {
+	var tst = &TestA{
+		FieldA: "one",
+		FieldG: []*TestC{{FieldA: "a"}},
+	}
+	assert.Error(t, tst.Checkpoint("start"))
+	tst.ResetMutableState(tst)
+	assert.NoError(t, tst.Checkpoint("start"))
+
+	// Step one
+	tst.FieldA = "two"
+	tst.FieldG[0].FieldA = "b"
+	assert.NoError(t, tst.Checkpoint("one"))
+	// Step two
+	tst.FieldB = 2
+	tst.FieldG = append(tst.FieldG, &TestC{FieldA: "c"})
+	assert.NoError(t, tst.Checkpoint("two"))
+
+	changes, err := tst.ChangesSince("one")
+	if assert.NoError(t, err) {
+		assert.Len(t, changes, 2)
+		assert.Equal(t, 2.0, changes["FieldB"].NewValue)
+		assert.Equal(t, Added, changes["FieldG"].NestedFields["1"].Status)
+	}
+	// No side effects
+	assert.Equal(t, NotChanged, tst.FieldG[1].MutableStatus)
+	assert.Equal(t, NotChanged, tst.MutableStatus)
+
+	changes, err = tst.ChangesBetween("start", "one")
+	if assert.NoError(t, err) {
+		assert.Len(t, changes, 2)
+		assert.Equal(t, "two", changes["FieldA"].NewValue)
+		assert.Equal(t, "b", changes["FieldG"].NestedFields["0"].NestedFields["FieldA"].NewValue)
+	}
+	changes, err = tst.ChangesBetween("one", "two")
+	if assert.NoError(t, err) {
+		assert.Len(t, changes, 2)
+	}
+	// Cumulative changes
+	assert.Len(t, tst.AnalyzeChanges(), 3)
+
+	// Restore
+	assert.NoError(t, tst.RestoreCheckpoint("one"))
+	assert.Equal(t, 0.0, tst.FieldB)
+	assert.Len(t, tst.FieldG, 1)
+	assert.Equal(t, "b", tst.FieldG[0].FieldA)
+	assert.Len(t, tst.AnalyzeChanges(), 2)
+	changes, _ = tst.ChangesSince("one")
+	assert.Empty(t, changes)
+	// Checkpoints are not affected by restored values
+	tst.FieldG[0].FieldA = "d"
+	changes, _ = tst.ChangesBetween("start", "one")
+	assert.Len(t, changes, 2)
+
+	tst.RemoveCheckpoint("two")
+	_, err = tst.ChangesSince("two")
+	assert.Error(t, err)
+	_, err = tst.ChangesBetween("two", "one")
+	assert.Error(t, err)
+	_, err = tst.ChangesBetween("one", "two")
+	assert.Error(t, err)
+	assert.Error(t, tst.RestoreCheckpoint("two"))
+}
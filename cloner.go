@@ -0,0 +1,8 @@
+package mutable
+
+// Cloner is the interface that wraps custom CloneForMutable function allowing to make a deep copy
+// of an object for original state snapshots (useful for types with unexported state, e.g. big.Int)
+// CloneForMutable should return a value of the same type or a pointer to it
+type Cloner interface {
+	CloneForMutable() interface{}
+}
@@ -0,0 +1,463 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	mutablePath  = "github.com/askretov/mutable"
+	mutFieldName = "Mutable"
+	mutTagName   = "mutable"
+)
+
+// basicTypes is a set of predeclared types which values are compared with == operator
+var basicTypes = map[string]bool{
+	"bool": true, "string": true, "byte": true, "rune": true, "uintptr": true,
+	"int": true, "int8": true, "int16": true, "int32": true, "int64": true,
+	"uint": true, "uint8": true, "uint16": true, "uint32": true, "uint64": true,
+	"float32": true, "float64": true, "complex64": true, "complex128": true,
+}
+
+// fieldKind is a kind of a struct field from the generated code point of view
+type fieldKind int
+
+const (
+	kindOther  fieldKind = iota // Field is handled with reflection
+	kindBasic                   // Field of a type comparable with == operator
+	kindStruct                  // Field of a struct type declared in the same package
+)
+
+// field contains parsed struct field data
+type field struct {
+	name     string    // Real field name
+	jsonName string    // JSON name of a field (a real name if a field has no JSON name)
+	tag      string    // Raw tag value
+	ignored  bool      // Field has ignore tag option
+	deep     bool      // Field has deep tag option
+	kind     fieldKind // Kind of a field
+	typeName string    // Name of a field type (for basic and struct kinds)
+}
+
+// structType contains parsed struct type data
+type structType struct {
+	name    string
+	mutable bool // Struct embeds mutable.Mutable
+	fields  []field
+}
+
+// pkgInfo contains parsed package data
+type pkgInfo struct {
+	name     string
+	order    []string               // Struct names in order of declaration
+	structs  map[string]*structType // Structs by names
+	typeSpec map[string]ast.Expr    // Types of all declared type names
+	equalers map[string]bool        // Type names having Equal method
+}
+
+// generate parses a package within dir and returns generated source code of mutable methods.
+// If types is empty, all mutable types are generated
+func generate(dir, outputName string, types []string) ([]byte, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go") && info.Name() != outputName
+	}, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("expected one package within %s, found %d", dir, len(pkgs))
+	}
+	for _, pkg := range pkgs {
+		return generatePackage(parsePackage(pkg), types)
+	}
+	return nil, nil
+}
+
+// parsePackage collects struct types data of pkg
+func parsePackage(pkg *ast.Package) *pkgInfo {
+	info := &pkgInfo{
+		name:     pkg.Name,
+		structs:  map[string]*structType{},
+		typeSpec: map[string]ast.Expr{},
+		equalers: map[string]bool{},
+	}
+	// Sort file names to get a stable declaration order
+	fileNames := make([]string, 0, len(pkg.Files))
+	for fileName := range pkg.Files {
+		fileNames = append(fileNames, fileName)
+	}
+	sort.Strings(fileNames)
+	var specs []*ast.TypeSpec
+	var specImports []string
+	for _, fileName := range fileNames {
+		file := pkg.Files[fileName]
+		mutableImport := importName(file, mutablePath)
+		for _, decl := range file.Decls {
+			switch decl := decl.(type) {
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					if typeSpec, ok := spec.(*ast.TypeSpec); ok {
+						info.typeSpec[typeSpec.Name.Name] = typeSpec.Type
+						specs = append(specs, typeSpec)
+						specImports = append(specImports, mutableImport)
+					}
+				}
+			case *ast.FuncDecl:
+				if decl.Recv != nil && decl.Name.Name == "Equal" && len(decl.Recv.List) > 0 {
+					info.equalers[receiverTypeName(decl.Recv.List[0].Type)] = true
+				}
+			}
+		}
+	}
+	for i, spec := range specs {
+		structExpr, ok := spec.Type.(*ast.StructType)
+		if !ok {
+			continue
+		}
+		st := &structType{name: spec.Name.Name}
+		for _, f := range structExpr.Fields.List {
+			var tag string
+			if f.Tag != nil {
+				tag, _ = strconv.Unquote(f.Tag.Value)
+			}
+			if len(f.Names) == 0 {
+				// Embedded field
+				if sel, ok := f.Type.(*ast.SelectorExpr); ok && sel.Sel.Name == mutFieldName && isIdent(sel.X, specImports[i]) {
+					st.mutable = true
+					continue
+				}
+				st.fields = append(st.fields, info.newField(embeddedName(f.Type), tag, f.Type))
+				continue
+			}
+			for _, name := range f.Names {
+				st.fields = append(st.fields, info.newField(name.Name, tag, f.Type))
+			}
+		}
+		info.structs[st.name] = st
+		info.order = append(info.order, st.name)
+	}
+	return info
+}
+
+// newField returns parsed field data
+func (p *pkgInfo) newField(name, tag string, typeExpr ast.Expr) field {
+	f := field{
+		name:     name,
+		jsonName: jsonName(name, tag),
+		tag:      tag,
+	}
+	tagValue, _ := reflect.StructTag(tag).Lookup(mutTagName)
+	for _, option := range strings.Split(tagValue, ",") {
+		switch strings.TrimSpace(option) {
+		case "ignore", "ignored":
+			f.ignored = true
+		case "deep":
+			f.deep = true
+		}
+	}
+	if ident, ok := typeExpr.(*ast.Ident); ok {
+		f.typeName = ident.Name
+		switch {
+		case p.isBasic(ident.Name):
+			f.kind = kindBasic
+		case p.isStruct(ident.Name):
+			f.kind = kindStruct
+		}
+	}
+	return f
+}
+
+// isBasic reports whether a type with typeName is comparable with == operator and has no Equal method
+func (p *pkgInfo) isBasic(typeName string) bool {
+	for i := 0; i < len(p.typeSpec)+1; i++ {
+		if p.equalers[typeName] {
+			return false
+		}
+		typeExpr, declared := p.typeSpec[typeName]
+		if !declared {
+			return basicTypes[typeName]
+		}
+		ident, ok := typeExpr.(*ast.Ident)
+		if !ok {
+			return false
+		}
+		typeName = ident.Name
+	}
+	return false
+}
+
+// isStruct reports whether typeName is a struct type declared in the package
+func (p *pkgInfo) isStruct(typeName string) bool {
+	_, ok := p.typeSpec[typeName].(*ast.StructType)
+	return ok
+}
+
+// generatePackage returns generated source code for types of a package
+func generatePackage(p *pkgInfo, types []string) ([]byte, error) {
+	selected := map[string]bool{}
+	for _, name := range types {
+		st, ok := p.structs[name]
+		if !ok {
+			return nil, fmt.Errorf("struct type %s not found", name)
+		}
+		if !st.mutable {
+			return nil, fmt.Errorf("type %s doesn't embed mutable.Mutable", name)
+		}
+		selected[name] = true
+	}
+	if len(types) == 0 {
+		for _, name := range p.order {
+			if p.structs[name].mutable {
+				selected[name] = true
+			}
+		}
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no mutable types found in package %s", p.name)
+	}
+	// Collect nested struct types handled with generated helpers
+	generated := map[string]bool{}
+	var collect func(name string)
+	collect = func(name string) {
+		if generated[name] {
+			return
+		}
+		generated[name] = true
+		for _, f := range p.structs[name].fields {
+			if f.kind == kindStruct {
+				collect(f.typeName)
+			}
+		}
+	}
+	for _, name := range p.order {
+		if selected[name] {
+			collect(name)
+		}
+	}
+
+	g := &generator{pkg: p, generated: generated}
+	for _, name := range p.order {
+		if selected[name] {
+			g.genMutabler(p.structs[name])
+		}
+	}
+	for _, name := range p.order {
+		if generated[name] {
+			g.genHelpers(p.structs[name])
+		}
+	}
+	return g.source()
+}
+
+// generator accumulates generated code
+type generator struct {
+	pkg        *pkgInfo
+	generated  map[string]bool // Struct types having generated helpers
+	buf        bytes.Buffer
+	useStrings bool // Generated code uses strings package
+}
+
+// printf writes formatted code
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+// source returns formatted source code of a generated file
+func (g *generator) source() ([]byte, error) {
+	var src bytes.Buffer
+	fmt.Fprintf(&src, "// Code generated by mutablegen. DO NOT EDIT.\n\npackage %s\n\nimport (\n", g.pkg.name)
+	if g.useStrings {
+		fmt.Fprintf(&src, "\t\"strings\"\n\n")
+	}
+	fmt.Fprintf(&src, "\t%q\n)\n", mutablePath)
+	src.Write(g.buf.Bytes())
+	formatted, err := format.Source(src.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("cannot format generated code: %s", err)
+	}
+	return formatted, nil
+}
+
+// isNested reports whether a field is a nested struct handled with generated helpers
+func (g *generator) isNested(f field) bool {
+	return f.kind == kindStruct && g.generated[f.typeName]
+}
+
+// genMutabler generates Mutabler methods and AnalyzeChangesForce of a mutable struct type
+func (g *generator) genMutabler(st *structType) {
+	g.printf("\n// ResetMutableState resets current mutable state and updates original state with given self value\n")
+	g.printf("// It also resets all nested mutable objects\n")
+	g.printf("func (m *%s) ResetMutableState(self interface{}) error {\n", st.name)
+	g.printf("if err := mutable.GenReset(&m.Mutable, self, m.mutableSnapshot()); err != nil {\nreturn err\n}\n")
+	for _, f := range st.fields {
+		switch {
+		case !isExported(f.name) || f.kind == kindBasic:
+		case g.isNested(f) && g.pkg.structs[f.typeName].mutable:
+			g.printf("if err := m.%s.ResetMutableState(&m.%[1]s); err != nil {\nreturn err\n}\n", f.name)
+		case f.kind != kindStruct || !g.isNested(f):
+			g.printf("if err := mutable.GenResetNested(&m.%s); err != nil {\nreturn err\n}\n", f.name)
+		}
+	}
+	g.printf("return nil\n}\n")
+
+	g.printf("\n// SetValue sets a value for given field by its name\n")
+	g.printf("func (m *%s) SetValue(fieldName string, value interface{}) error {\n", st.name)
+	g.printf("return mutable.GenSetValue(&m.Mutable, fieldName, value, m.mutableSetField)\n}\n")
+
+	g.printf("\n// AnalyzeChanges analyzes changes of a target object and returns changed fields data\n")
+	g.printf("func (m *%s) AnalyzeChanges() mutable.ChangedFields {\n", st.name)
+	g.printf("return mutable.GenAnalyzeChanges(&m.Mutable, false, m.mutableAnalyze)\n}\n")
+
+	g.printf("\n// AnalyzeChangesForce analyzes changes of a target object regardless of cached changes and returns changed fields data\n")
+	g.printf("func (m *%s) AnalyzeChangesForce() mutable.ChangedFields {\n", st.name)
+	g.printf("return mutable.GenAnalyzeChanges(&m.Mutable, true, m.mutableAnalyze)\n}\n")
+
+	g.printf("\n// mutableAnalyze returns changed fields of m compared to an original state\n")
+	g.printf("func (m *%s) mutableAnalyze(originalState interface{}) mutable.ChangedFields {\n", st.name)
+	g.printf("original, ok := originalState.(%s)\nif !ok {\nreturn mutable.ChangedFields{}\n}\n", st.name)
+	g.printf("return m.mutableChanges(&original)\n}\n")
+}
+
+// genHelpers generates snapshot, analyze and set helper methods of a struct type
+func (g *generator) genHelpers(st *structType) {
+	g.printf("\n// mutableSnapshot returns a deep copy of m\n")
+	g.printf("func (m *%s) mutableSnapshot() %[1]s {\nsnapshot := *m\n", st.name)
+	if st.mutable {
+		g.printf("snapshot.Mutable = mutable.Mutable{}\n")
+	}
+	for _, f := range st.fields {
+		switch {
+		case !isExported(f.name) || f.kind == kindBasic:
+			// Unexported fields are copied as is
+		case g.isNested(f):
+			g.printf("snapshot.%s = m.%[1]s.mutableSnapshot()\n", f.name)
+		default:
+			g.printf("mutable.GenDeepCopy(&snapshot.%s)\n", f.name)
+		}
+	}
+	g.printf("return snapshot\n}\n")
+
+	g.printf("\n// mutableChanges returns changed fields of m compared to original\n")
+	g.printf("func (m *%s) mutableChanges(original *%[1]s) mutable.ChangedFields {\n", st.name)
+	g.printf("changedFields := mutable.ChangedFields{}\n")
+	for _, f := range st.fields {
+		switch {
+		case !isExported(f.name) || f.ignored:
+		case f.kind == kindBasic:
+			g.printf("if m.%s != original.%[1]s {\n", f.name)
+			g.printf("changedFields[%q] = &mutable.ChangedField{Name: %[1]q, JSONName: %q, OldValue: original.%[1]s, NewValue: m.%[1]s}\n}\n", f.name, f.jsonName)
+		case g.isNested(f) && f.deep:
+			g.printf("if nestedFields := m.%s.mutableChanges(&original.%[1]s); len(nestedFields) > 0 {\n", f.name)
+			g.printf("changedFields[%q] = &mutable.ChangedField{Name: %[1]q, JSONName: %q, NestedFields: nestedFields}\n}\n", f.name, f.jsonName)
+		default:
+			g.printf("if changedField := mutable.GenAnalyzeField(%q, %q, &m.%[1]s, &original.%[1]s); changedField != nil {\n", f.name, f.tag)
+			g.printf("changedFields[%q] = changedField\n}\n", f.name)
+		}
+	}
+	if st.mutable {
+		g.printf("mutable.GenUpdateState(&m.Mutable, changedFields)\n")
+	}
+	g.printf("return changedFields\n}\n")
+
+	g.printf("\n// mutableSetField sets a value for given field by its name. It reports false if a field is not handled\n")
+	g.printf("func (m *%s) mutableSetField(fieldName string, value interface{}) (bool, error) {\n", st.name)
+	g.printf("switch fieldName {\n")
+	for _, f := range st.fields {
+		switch {
+		case !isExported(f.name):
+		case f.kind == kindBasic:
+			g.printf("case %q:\n", f.jsonName)
+			g.printf("if v, ok := value.(%s); ok {\nm.%s = v\nreturn true, nil\n}\n", f.typeName, f.name)
+			g.printf("return true, mutable.GenParse(fieldName, value, &m.%s)\n", f.name)
+		case f.kind == kindStruct:
+			g.printf("case %q:\n", f.jsonName)
+			g.printf("return true, mutable.GenParse(fieldName, value, &m.%s)\n", f.name)
+		}
+	}
+	g.printf("}\n")
+	for _, f := range st.fields {
+		if isExported(f.name) && g.isNested(f) {
+			g.useStrings = true
+			g.printf("if prefix := %q + mutable.LevelSeparator; strings.HasPrefix(fieldName, prefix) {\n", f.jsonName)
+			if !g.pkg.structs[f.typeName].mutable {
+				g.printf("return m.%s.mutableSetField(fieldName[len(prefix):], value)\n}\n", f.name)
+				continue
+			}
+			// Analyzed changes of a nested mutable object are invalidated once a field within it is set
+			g.printf("handled, err := m.%s.mutableSetField(fieldName[len(prefix):], value)\n", f.name)
+			g.printf("if handled {\nmutable.GenInvalidate(&m.%s.Mutable)\n}\n", f.name)
+			g.printf("return handled, err\n}\n")
+		}
+	}
+	g.printf("return false, nil\n}\n")
+}
+
+// importName returns a name of an import with path within file or an empty string if it's not imported
+func importName(file *ast.File, path string) string {
+	for _, spec := range file.Imports {
+		if importPath, _ := strconv.Unquote(spec.Path.Value); importPath == path {
+			if spec.Name != nil {
+				return spec.Name.Name
+			}
+			return path[strings.LastIndex(path, "/")+1:]
+		}
+	}
+	return ""
+}
+
+// jsonName returns a JSON name of a field or its real name if a field has no JSON name
+func jsonName(name, tag string) string {
+	tagValue, ok := reflect.StructTag(tag).Lookup("json")
+	if !ok || tagValue == "-" {
+		return name
+	}
+	if jsonName := strings.Split(tagValue, ",")[0]; len(jsonName) > 0 {
+		return jsonName
+	}
+	return name
+}
+
+// embeddedName returns a field name of an embedded type
+func embeddedName(typeExpr ast.Expr) string {
+	switch t := typeExpr.(type) {
+	case *ast.StarExpr:
+		return embeddedName(t.X)
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	case *ast.Ident:
+		return t.Name
+	}
+	return ""
+}
+
+// receiverTypeName returns a type name of a method receiver
+func receiverTypeName(typeExpr ast.Expr) string {
+	if star, ok := typeExpr.(*ast.StarExpr); ok {
+		typeExpr = star.X
+	}
+	if ident, ok := typeExpr.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
+
+// isIdent reports whether expr is an identifier with a given name
+func isIdent(expr ast.Expr, name string) bool {
+	ident, ok := expr.(*ast.Ident)
+	return ok && len(name) > 0 && ident.Name == name
+}
+
+// isExported reports whether a field name is exported
+func isExported(name string) bool {
+	return ast.IsExported(name)
+}
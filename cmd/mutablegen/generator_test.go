@@ -0,0 +1,25 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerate(t *testing.T) {
+	dir := filepath.Join("internal", "sample")
+	// Generated code must be up to date with the committed one
+	src, err := generate(dir, defaultOutput, []string{"Car"})
+	if assert.NoError(t, err) {
+		expected, err := os.ReadFile(filepath.Join(dir, defaultOutput))
+		assert.NoError(t, err)
+		assert.Equal(t, string(expected), string(src))
+	}
+	// Unknown and not mutable types
+	_, err = generate(dir, defaultOutput, []string{"Unknown"})
+	assert.Error(t, err)
+	_, err = generate(dir, defaultOutput, []string{"Owner"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,218 @@
+// Code generated by mutablegen. DO NOT EDIT.
+
+package sample
+
+import (
+	"strings"
+
+	"github.com/askretov/mutable"
+)
+
+// ResetMutableState resets current mutable state and updates original state with given self value
+// It also resets all nested mutable objects
+func (m *Car) ResetMutableState(self interface{}) error {
+	if err := mutable.GenReset(&m.Mutable, self, m.mutableSnapshot()); err != nil {
+		return err
+	}
+	if err := m.Engine.ResetMutableState(&m.Engine); err != nil {
+		return err
+	}
+	if err := mutable.GenResetNested(&m.Tags); err != nil {
+		return err
+	}
+	if err := mutable.GenResetNested(&m.Options); err != nil {
+		return err
+	}
+	if err := mutable.GenResetNested(&m.Spare); err != nil {
+		return err
+	}
+	return nil
+}
+
+// SetValue sets a value for given field by its name
+func (m *Car) SetValue(fieldName string, value interface{}) error {
+	return mutable.GenSetValue(&m.Mutable, fieldName, value, m.mutableSetField)
+}
+
+// AnalyzeChanges analyzes changes of a target object and returns changed fields data
+func (m *Car) AnalyzeChanges() mutable.ChangedFields {
+	return mutable.GenAnalyzeChanges(&m.Mutable, false, m.mutableAnalyze)
+}
+
+// AnalyzeChangesForce analyzes changes of a target object regardless of cached changes and returns changed fields data
+func (m *Car) AnalyzeChangesForce() mutable.ChangedFields {
+	return mutable.GenAnalyzeChanges(&m.Mutable, true, m.mutableAnalyze)
+}
+
+// mutableAnalyze returns changed fields of m compared to an original state
+func (m *Car) mutableAnalyze(originalState interface{}) mutable.ChangedFields {
+	original, ok := originalState.(Car)
+	if !ok {
+		return mutable.ChangedFields{}
+	}
+	return m.mutableChanges(&original)
+}
+
+// mutableSnapshot returns a deep copy of m
+func (m *Engine) mutableSnapshot() Engine {
+	snapshot := *m
+	snapshot.Mutable = mutable.Mutable{}
+	return snapshot
+}
+
+// mutableChanges returns changed fields of m compared to original
+func (m *Engine) mutableChanges(original *Engine) mutable.ChangedFields {
+	changedFields := mutable.ChangedFields{}
+	if m.Power != original.Power {
+		changedFields["Power"] = &mutable.ChangedField{Name: "Power", JSONName: "power", OldValue: original.Power, NewValue: m.Power}
+	}
+	if m.Model != original.Model {
+		changedFields["Model"] = &mutable.ChangedField{Name: "Model", JSONName: "model", OldValue: original.Model, NewValue: m.Model}
+	}
+	mutable.GenUpdateState(&m.Mutable, changedFields)
+	return changedFields
+}
+
+// mutableSetField sets a value for given field by its name. It reports false if a field is not handled
+func (m *Engine) mutableSetField(fieldName string, value interface{}) (bool, error) {
+	switch fieldName {
+	case "power":
+		if v, ok := value.(int); ok {
+			m.Power = v
+			return true, nil
+		}
+		return true, mutable.GenParse(fieldName, value, &m.Power)
+	case "model":
+		if v, ok := value.(string); ok {
+			m.Model = v
+			return true, nil
+		}
+		return true, mutable.GenParse(fieldName, value, &m.Model)
+	case "Serial":
+		if v, ok := value.(string); ok {
+			m.Serial = v
+			return true, nil
+		}
+		return true, mutable.GenParse(fieldName, value, &m.Serial)
+	}
+	return false, nil
+}
+
+// mutableSnapshot returns a deep copy of m
+func (m *Owner) mutableSnapshot() Owner {
+	snapshot := *m
+	return snapshot
+}
+
+// mutableChanges returns changed fields of m compared to original
+func (m *Owner) mutableChanges(original *Owner) mutable.ChangedFields {
+	changedFields := mutable.ChangedFields{}
+	if m.Name != original.Name {
+		changedFields["Name"] = &mutable.ChangedField{Name: "Name", JSONName: "name", OldValue: original.Name, NewValue: m.Name}
+	}
+	if m.Age != original.Age {
+		changedFields["Age"] = &mutable.ChangedField{Name: "Age", JSONName: "age", OldValue: original.Age, NewValue: m.Age}
+	}
+	return changedFields
+}
+
+// mutableSetField sets a value for given field by its name. It reports false if a field is not handled
+func (m *Owner) mutableSetField(fieldName string, value interface{}) (bool, error) {
+	switch fieldName {
+	case "name":
+		if v, ok := value.(string); ok {
+			m.Name = v
+			return true, nil
+		}
+		return true, mutable.GenParse(fieldName, value, &m.Name)
+	case "age":
+		if v, ok := value.(int); ok {
+			m.Age = v
+			return true, nil
+		}
+		return true, mutable.GenParse(fieldName, value, &m.Age)
+	}
+	return false, nil
+}
+
+// mutableSnapshot returns a deep copy of m
+func (m *Car) mutableSnapshot() Car {
+	snapshot := *m
+	snapshot.Mutable = mutable.Mutable{}
+	snapshot.Engine = m.Engine.mutableSnapshot()
+	snapshot.Owner = m.Owner.mutableSnapshot()
+	mutable.GenDeepCopy(&snapshot.Tags)
+	mutable.GenDeepCopy(&snapshot.Options)
+	mutable.GenDeepCopy(&snapshot.Spare)
+	return snapshot
+}
+
+// mutableChanges returns changed fields of m compared to original
+func (m *Car) mutableChanges(original *Car) mutable.ChangedFields {
+	changedFields := mutable.ChangedFields{}
+	if m.Brand != original.Brand {
+		changedFields["Brand"] = &mutable.ChangedField{Name: "Brand", JSONName: "brand", OldValue: original.Brand, NewValue: m.Brand}
+	}
+	if m.Price != original.Price {
+		changedFields["Price"] = &mutable.ChangedField{Name: "Price", JSONName: "price", OldValue: original.Price, NewValue: m.Price}
+	}
+	if m.Level != original.Level {
+		changedFields["Level"] = &mutable.ChangedField{Name: "Level", JSONName: "level", OldValue: original.Level, NewValue: m.Level}
+	}
+	if nestedFields := m.Engine.mutableChanges(&original.Engine); len(nestedFields) > 0 {
+		changedFields["Engine"] = &mutable.ChangedField{Name: "Engine", JSONName: "engine", NestedFields: nestedFields}
+	}
+	if changedField := mutable.GenAnalyzeField("Owner", "json:\"owner\"", &m.Owner, &original.Owner); changedField != nil {
+		changedFields["Owner"] = changedField
+	}
+	if changedField := mutable.GenAnalyzeField("Tags", "json:\"tags\"", &m.Tags, &original.Tags); changedField != nil {
+		changedFields["Tags"] = changedField
+	}
+	if changedField := mutable.GenAnalyzeField("Options", "json:\"options\"", &m.Options, &original.Options); changedField != nil {
+		changedFields["Options"] = changedField
+	}
+	if changedField := mutable.GenAnalyzeField("Spare", "json:\"spare\"", &m.Spare, &original.Spare); changedField != nil {
+		changedFields["Spare"] = changedField
+	}
+	mutable.GenUpdateState(&m.Mutable, changedFields)
+	return changedFields
+}
+
+// mutableSetField sets a value for given field by its name. It reports false if a field is not handled
+func (m *Car) mutableSetField(fieldName string, value interface{}) (bool, error) {
+	switch fieldName {
+	case "brand":
+		if v, ok := value.(string); ok {
+			m.Brand = v
+			return true, nil
+		}
+		return true, mutable.GenParse(fieldName, value, &m.Brand)
+	case "price":
+		if v, ok := value.(float64); ok {
+			m.Price = v
+			return true, nil
+		}
+		return true, mutable.GenParse(fieldName, value, &m.Price)
+	case "level":
+		if v, ok := value.(Level); ok {
+			m.Level = v
+			return true, nil
+		}
+		return true, mutable.GenParse(fieldName, value, &m.Level)
+	case "engine":
+		return true, mutable.GenParse(fieldName, value, &m.Engine)
+	case "owner":
+		return true, mutable.GenParse(fieldName, value, &m.Owner)
+	}
+	if prefix := "engine" + mutable.LevelSeparator; strings.HasPrefix(fieldName, prefix) {
+		handled, err := m.Engine.mutableSetField(fieldName[len(prefix):], value)
+		if handled {
+			mutable.GenInvalidate(&m.Engine.Mutable)
+		}
+		return handled, err
+	}
+	if prefix := "owner" + mutable.LevelSeparator; strings.HasPrefix(fieldName, prefix) {
+		return m.Owner.mutableSetField(fieldName[len(prefix):], value)
+	}
+	return false, nil
+}
@@ -0,0 +1,39 @@
+// Package sample contains types used to test code generated by mutablegen
+package sample
+
+//go:generate go run ../.. -type=Car
+
+import (
+	"github.com/askretov/mutable"
+)
+
+// Level is a named basic type
+type Level int
+
+// Engine is a nested mutable struct
+type Engine struct {
+	mutable.Mutable
+	Power  int    `json:"power"`
+	Model  string `json:"model"`
+	Serial string `mutable:"ignore"`
+}
+
+// Owner is a nested plain struct
+type Owner struct {
+	Name string `json:"name"`
+	Age  int    `json:"age"`
+}
+
+// Car is a sample mutable struct
+type Car struct {
+	mutable.Mutable
+	Brand   string            `json:"brand"`
+	Price   float64           `json:"price"`
+	Level   Level             `json:"level"`
+	Engine  Engine            `json:"engine" mutable:"deep"`
+	Owner   Owner             `json:"owner"`
+	Tags    []string          `json:"tags"`
+	Options map[string]string `json:"options"`
+	Spare   *Engine           `json:"spare"`
+	secret  string
+}
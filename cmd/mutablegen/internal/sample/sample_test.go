@@ -0,0 +1,97 @@
+package sample
+
+import (
+	"testing"
+
+	"github.com/askretov/mutable"
+	"github.com/stretchr/testify/assert"
+)
+
+func newCar() *Car {
+	car := &Car{
+		Brand:   "BMW",
+		Price:   100,
+		Level:   1,
+		Engine:  Engine{Power: 200, Model: "N52"},
+		Owner:   Owner{Name: "John", Age: 30},
+		Tags:    []string{"one"},
+		Options: map[string]string{"color": "red"},
+		Spare:   &Engine{Power: 100},
+	}
+	car.ResetMutableState(car)
+	return car
+}
+
+func TestCar_AnalyzeChanges(t *testing.T) {
+	car := newCar()
+	assert.Empty(t, car.AnalyzeChanges())
+	assert.Equal(t, mutable.NotChanged, car.MutableStatus)
+
+	assert.NoError(t, car.SetValue("brand", "Audi"))
+	assert.NoError(t, car.SetValue("price", "120.5"))
+	assert.NoError(t, car.SetValue("level", Level(2)))
+	assert.NoError(t, car.SetValue("engine/power", 250))
+	assert.NoError(t, car.SetValue("owner/name", "Jack"))
+	assert.NoError(t, car.SetValue("engine/Serial", "123"))
+	// Fields handled with reflection
+	assert.NoError(t, car.SetValue("tags", []string{"one", "two"}))
+	car.Options["color"] = "blue"
+	car.Spare.Power = 150
+	assert.Error(t, car.SetValue("unknown", 1))
+
+	changes := car.AnalyzeChanges()
+	assert.Equal(t, mutable.Changed, car.MutableStatus)
+	assert.Equal(t, "Audi", changes["Brand"].NewValue)
+	assert.Equal(t, 120.5, changes["Price"].NewValue)
+	assert.Equal(t, Level(2), changes["Level"].NewValue)
+	assert.Equal(t, 250, changes["Engine"].NestedFields["Power"].NewValue)
+	assert.Equal(t, mutable.Changed, car.Engine.MutableStatus)
+	assert.Contains(t, changes, "Owner")
+	assert.Contains(t, changes, "Tags")
+	assert.Contains(t, changes, "Options")
+	assert.Contains(t, changes, "Spare")
+	assert.Len(t, changes, 8)
+
+	// Original state is a deep copy
+	car.ResetMutableState(car)
+	car.Tags[0] = "three"
+	car.Options["color"] = "green"
+	changes = car.AnalyzeChangesForce()
+	assert.Len(t, changes, 2)
+
+	// Another path syntax is handled with reflection
+	car.SetPathSyntax(mutable.DotPath)
+	assert.NoError(t, car.SetValue("engine.power", 300))
+	assert.Equal(t, 300, car.Engine.Power)
+	assert.Error(t, car.SetValue("engine/power", 300))
+	assert.Equal(t, mutable.NotChanged, car.Engine.MutableStatus)
+}
+
+func TestCar_matchesReflection(t *testing.T) {
+	// Changes found by generated methods must match changes found with reflection
+	car := newCar()
+	reflected := newCar()
+	reflected.Mutable.ResetMutableState(reflected)
+	for _, c := range []*Car{car, reflected} {
+		c.Brand = "Audi"
+		c.Engine.Model = "B58"
+		c.Owner.Age = 31
+	}
+	expected := reflected.Mutable.AnalyzeChangesForce()
+	changes := car.AnalyzeChangesForce()
+	assert.Equal(t, len(expected), len(changes))
+	for name, field := range expected {
+		if assert.Contains(t, changes, name) {
+			assert.Equal(t, field.NewValue, changes[name].NewValue)
+			assert.Equal(t, len(field.NestedFields), len(changes[name].NestedFields))
+		}
+	}
+}
+
+func TestCar_SetValue_invalidatesNested(t *testing.T) {
+	car := newCar()
+	assert.Empty(t, car.Engine.AnalyzeChanges())
+	// Cached changes of a nested object are dropped once its field is set with a generated setter
+	assert.NoError(t, car.SetValue("engine/power", 250))
+	assert.Contains(t, car.Engine.AnalyzeChanges(), "Power")
+}
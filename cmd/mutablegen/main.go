@@ -0,0 +1,51 @@
+// Mutablegen generates reflection-free Mutabler methods for structs embedding mutable.Mutable.
+//
+// Usage (within a package directory or with go:generate):
+//
+//	//go:generate mutablegen [-type=TypeA,TypeB] [-output=mutable_gen.go]
+//
+// Generated ResetMutableState, SetValue, AnalyzeChanges and AnalyzeChangesForce methods honour the same
+// struct tags and JSON names as the reflection based implementation of Mutable. Fields of basic types and
+// nested structs declared in the same package are handled with generated code, other fields (pointers, slices,
+// maps, types of other packages) fall back to the reflection based implementation.
+// Run mutablegen once per package because generated helper methods are shared between types.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const defaultOutput = "mutable_gen.go"
+
+func main() {
+	typeNames := flag.String("type", "", "comma-separated list of type names (all mutable types by default)")
+	output := flag.String("output", defaultOutput, "output file name")
+	flag.Parse()
+
+	dir := "."
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+	var types []string
+	if len(*typeNames) > 0 {
+		types = strings.Split(*typeNames, ",")
+	}
+	outputPath := *output
+	if !filepath.IsAbs(outputPath) {
+		outputPath = filepath.Join(dir, outputPath)
+	}
+
+	src, err := generate(dir, filepath.Base(outputPath), types)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "mutablegen:", err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(outputPath, src, 0644); err != nil {
+		fmt.Fprintln(os.Stderr, "mutablegen:", err)
+		os.Exit(1)
+	}
+}
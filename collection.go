@@ -0,0 +1,403 @@
+package mutable
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/go-ext/logger"
+)
+
+// analyzeSlice returns changed field data of element-level analyze of a slice of mutable objects.
+// Elements are matched by keyField values if it's set, otherwise by the longest common subsequence of equal elements.
+// Every changed element is stored as a nested field with its key value or index as a name
+func analyzeSlice(fieldName string, current, original reflect.Value, keyField string) *ChangedField {
+	var nestedFields ChangedFields
+	if len(keyField) > 0 {
+		currentKeys, currentOk := sliceKeys(current, keyField)
+		originalKeys, originalOk := sliceKeys(original, keyField)
+		if currentOk && originalOk {
+			nestedFields = analyzeSliceByKey(current, original, currentKeys, originalKeys)
+		} else {
+			logger.Warningf("cannot match slice elements by a key (%s), field: %s", keyField, fieldName)
+		}
+	}
+	if nestedFields == nil {
+		nestedFields = analyzeSliceByLCS(current, original)
+	}
+	if len(nestedFields) == 0 {
+		return analyzeNilTransition(fieldName, current, original, reflect.Slice)
+	}
+	return &ChangedField{
+		Name:         fieldName,
+		NestedFields: nestedFields,
+		kind:         reflect.Slice,
+		current:      current.Interface(),
+		originalNil:  original.IsNil(),
+		currentNil:   current.IsNil(),
+	}
+}
+
+// analyzeSliceByKey returns changed fields of slice elements matched by their keys.
+// Matched elements which are not a part of the longest common subsequence of keys are reported as moved
+func analyzeSliceByKey(current, original reflect.Value, currentKeys, originalKeys []interface{}) ChangedFields {
+	currentIndexes := make(map[interface{}]int, len(currentKeys))
+	for i, key := range currentKeys {
+		currentIndexes[key] = i
+	}
+	originalIndexes := make(map[interface{}]int, len(originalKeys))
+	for i, key := range originalKeys {
+		originalIndexes[key] = i
+	}
+	// Get keys of elements existing in both slices
+	var currentCommon, originalCommon []interface{}
+	for _, key := range currentKeys {
+		if _, ok := originalIndexes[key]; ok {
+			currentCommon = append(currentCommon, key)
+		}
+	}
+	for _, key := range originalKeys {
+		if _, ok := currentIndexes[key]; ok {
+			originalCommon = append(originalCommon, key)
+		}
+	}
+	// Elements kept their relative order are not moved
+	notMoved := map[interface{}]bool{}
+	for _, match := range lcs(len(originalCommon), len(currentCommon), func(i, j int) bool {
+		return originalCommon[i] == currentCommon[j]
+	}) {
+		notMoved[originalCommon[match[0]]] = true
+	}
+
+	changedFields := ChangedFields{}
+	for i, key := range originalKeys {
+		if _, ok := currentIndexes[key]; !ok {
+			// Element has been removed
+			changedFields.add(fmt.Sprint(key), &ChangedField{
+				OldValue: removedInterface(original.Index(i)),
+				Status:   Removed,
+				OldIndex: i,
+				NewIndex: -1,
+			})
+		}
+	}
+	for j, key := range currentKeys {
+		i, ok := originalIndexes[key]
+		if !ok {
+			// Element has been added
+			changedFields.add(fmt.Sprint(key), &ChangedField{
+				NewValue: addedInterface(current.Index(j)),
+				Status:   Added,
+				OldIndex: -1,
+				NewIndex: j,
+			})
+			continue
+		}
+		changedField := analyzeElem(current.Index(j), original.Index(i))
+		if !notMoved[key] {
+			if changedField == nil {
+				changedField = &ChangedField{}
+			}
+			// Position of an element is a part of its state
+			updateMutableStatus(derefValue(current.Index(j)), true)
+			changedField.NewValue = elemInterface(current.Index(j))
+			changedField.Status = Moved
+		}
+		if changedField != nil {
+			changedField.OldIndex, changedField.NewIndex = i, j
+			changedFields.add(fmt.Sprint(key), changedField)
+		}
+	}
+	return changedFields
+}
+
+// analyzeSliceByLCS returns changed fields of slice elements matched by the longest common subsequence of equal elements.
+// Not matched elements between two matches are paired as changed ones, the rest of them are reported as removed or added.
+// Removed elements are named by their original indexes, all other elements by their current indexes.
+// If names of removed and another element collide, the element at that index is reported as replaced
+func analyzeSliceByLCS(current, original reflect.Value) ChangedFields {
+	var removed []int
+	added := map[int]bool{}
+	// Original indexes of paired elements by their current indexes
+	paired := map[int]int{}
+	i, j := 0, 0
+	matches := lcs(original.Len(), current.Len(), func(i, j int) bool {
+		return isEqual(original.Index(i), current.Index(j))
+	})
+	// Add a sentinel match to handle the tail of slices
+	matches = append(matches, [2]int{original.Len(), current.Len()})
+	for _, match := range matches {
+		for ; i < match[0] && j < match[1]; i, j = i+1, j+1 {
+			paired[j] = i
+		}
+		for ; i < match[0]; i++ {
+			removed = append(removed, i)
+		}
+		for ; j < match[1]; j++ {
+			added[j] = true
+		}
+		i, j = match[0]+1, match[1]+1
+	}
+
+	changedFields := ChangedFields{}
+	for len(removed) > 0 {
+		i, removed = removed[0], removed[1:]
+		if added[i] {
+			delete(added, i)
+		} else if pairedIndex, ok := paired[i]; ok {
+			// Unpair the element occupying the name
+			delete(paired, i)
+			removed = append(removed, pairedIndex)
+		} else {
+			// Element has been removed
+			changedFields.add(strconv.Itoa(i), &ChangedField{
+				OldValue: removedInterface(original.Index(i)),
+				Status:   Removed,
+				OldIndex: i,
+				NewIndex: -1,
+			})
+			continue
+		}
+		// Element has been replaced with another one
+		changedFields.add(strconv.Itoa(i), &ChangedField{
+			OldValue: removedInterface(original.Index(i)),
+			NewValue: addedInterface(current.Index(i)),
+			Status:   Changed,
+			OldIndex: i,
+			NewIndex: i,
+		})
+	}
+	for j := range added {
+		// Element has been added
+		changedFields.add(strconv.Itoa(j), &ChangedField{
+			NewValue: addedInterface(current.Index(j)),
+			Status:   Added,
+			OldIndex: -1,
+			NewIndex: j,
+		})
+	}
+	for j, i := range paired {
+		if changedField := analyzeElem(current.Index(j), original.Index(i)); changedField != nil {
+			changedField.OldIndex, changedField.NewIndex = i, j
+			changedFields.add(strconv.Itoa(j), changedField)
+		}
+	}
+	return changedFields
+}
+
+// analyzeMap returns changed field data of per key analyze of a map.
+// Every added, removed or changed element is stored as a nested field with its key as a name.
+// Changes of mutable elements are analyzed per field
+func analyzeMap(fieldName string, current, original reflect.Value) *ChangedField {
+	isMutableElem := isMutableElemType(current.Type())
+	nestedFields := ChangedFields{}
+	for _, key := range original.MapKeys() {
+		if !current.MapIndex(key).IsValid() {
+			// Element has been removed
+			nestedFields.add(fmt.Sprint(key.Interface()), &ChangedField{
+				OldValue: removedInterface(original.MapIndex(key)),
+				Status:   Removed,
+			})
+		}
+	}
+	for _, key := range current.MapKeys() {
+		currentElem, originalElem := current.MapIndex(key), original.MapIndex(key)
+		isElemCopy := false
+		if isMutableElem && currentElem.Kind() != reflect.Ptr {
+			// Map elements are not addressable, so analyze a copy and put it back to keep its mutable state
+			elemCopy := reflect.New(currentElem.Type()).Elem()
+			elemCopy.Set(currentElem)
+			currentElem, isElemCopy = elemCopy, true
+		}
+		var changedField *ChangedField
+		switch {
+		case !originalElem.IsValid():
+			// Element has been added
+			changedField = &ChangedField{
+				NewValue: addedInterface(currentElem),
+				Status:   Added,
+			}
+		case isMutableElem:
+			changedField = analyzeElem(currentElem, originalElem)
+		case !isEqual(currentElem, originalElem):
+			changedField = &ChangedField{
+				OldValue: elemInterface(originalElem),
+				NewValue: elemInterface(currentElem),
+				Status:   Changed,
+			}
+		}
+		if isElemCopy {
+			current.SetMapIndex(key, currentElem)
+		}
+		if changedField != nil {
+			nestedFields.add(fmt.Sprint(key.Interface()), changedField)
+		}
+	}
+	if len(nestedFields) == 0 {
+		return analyzeNilTransition(fieldName, current, original, reflect.Map)
+	}
+	return &ChangedField{
+		Name:         fieldName,
+		NestedFields: nestedFields,
+		kind:         reflect.Map,
+		current:      current.Interface(),
+		originalNil:  original.IsNil(),
+		currentNil:   current.IsNil(),
+	}
+}
+
+// analyzeNilTransition returns a change of a whole collection which has become nil or not nil
+// without changes of elements (eg. nil and empty maps), otherwise it returns nil
+func analyzeNilTransition(fieldName string, current, original reflect.Value, kind reflect.Kind) *ChangedField {
+	if current.IsNil() == original.IsNil() {
+		return nil
+	}
+	return &ChangedField{
+		Name:        fieldName,
+		OldValue:    original.Interface(),
+		NewValue:    current.Interface(),
+		kind:        kind,
+		current:     current.Interface(),
+		originalNil: original.IsNil(),
+		currentNil:  current.IsNil(),
+	}
+}
+
+// sliceKeys returns values of keyField of v elements.
+// It reports false if some element has no comparable key field value or keys are not unique
+func sliceKeys(v reflect.Value, keyField string) ([]interface{}, bool) {
+	keys := make([]interface{}, 0, v.Len())
+	unique := make(map[interface{}]bool, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		elem := v.Index(i)
+		if elem.Kind() == reflect.Ptr {
+			if elem.IsNil() {
+				return nil, false
+			}
+			elem = elem.Elem()
+		}
+		keyFieldMeta := getTypeInfo(elem.Type()).fieldByName(keyField)
+		if keyFieldMeta == nil {
+			return nil, false
+		}
+		key := elem.Field(keyFieldMeta.index)
+		if !key.CanInterface() || !key.Type().Comparable() {
+			return nil, false
+		}
+		if unique[key.Interface()] {
+			return nil, false
+		}
+		unique[key.Interface()] = true
+		keys = append(keys, key.Interface())
+	}
+	return keys, true
+}
+
+// lcs returns index pairs of the longest common subsequence of two sequences with n and m lengths.
+// equal reports whether i element of the first sequence is equal to j element of the second one
+func lcs(n, m int, equal func(i, j int) bool) [][2]int {
+	var matches [][2]int
+	// Skip common prefix and suffix
+	prefix := 0
+	for prefix < n && prefix < m && equal(prefix, prefix) {
+		matches = append(matches, [2]int{prefix, prefix})
+		prefix++
+	}
+	suffix := 0
+	for suffix < n-prefix && suffix < m-prefix && equal(n-1-suffix, m-1-suffix) {
+		suffix++
+	}
+	// Fill the table of common subsequence lengths of sequences tails
+	rows, cols := n-prefix-suffix, m-prefix-suffix
+	lengths := make([][]int, rows+1)
+	for i := range lengths {
+		lengths[i] = make([]int, cols+1)
+	}
+	for i := rows - 1; i >= 0; i-- {
+		for j := cols - 1; j >= 0; j-- {
+			if equal(prefix+i, prefix+j) {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else if lengths[i+1][j] >= lengths[i][j+1] {
+				lengths[i][j] = lengths[i+1][j]
+			} else {
+				lengths[i][j] = lengths[i][j+1]
+			}
+		}
+	}
+	// Collect matches
+	for i, j := 0, 0; i < rows && j < cols; {
+		switch {
+		case lengths[i][j] == lengths[i+1][j+1]+1 && equal(prefix+i, prefix+j):
+			matches = append(matches, [2]int{prefix + i, prefix + j})
+			i, j = i+1, j+1
+		case lengths[i+1][j] > lengths[i][j+1]:
+			// Prefer to keep elements of the first sequence
+			i++
+		default:
+			j++
+		}
+	}
+	for k := suffix; k > 0; k-- {
+		matches = append(matches, [2]int{n - k, m - k})
+	}
+	return matches
+}
+
+// analyzeElem returns changed field data of a mutable collection element or nil if it has not been changed
+func analyzeElem(current, original reflect.Value) *ChangedField {
+	if current.Kind() == reflect.Ptr {
+		if current.IsNil() || original.IsNil() {
+			if current.IsNil() && original.IsNil() {
+				return nil
+			}
+			// Element has been replaced with nil or vice versa
+			return &ChangedField{
+				OldValue: removedInterface(original),
+				NewValue: addedInterface(current),
+				Status:   Changed,
+			}
+		}
+		current, original = current.Elem(), original.Elem()
+	}
+	if nestedFields := tryAnalyzeChanges(current, original); len(nestedFields) > 0 {
+		return &ChangedField{
+			NestedFields: nestedFields,
+			Status:       Changed,
+		}
+	}
+	return nil
+}
+
+// derefValue returns a value v points to if it's a pointer, otherwise v itself
+func derefValue(v reflect.Value) reflect.Value {
+	if v.Kind() == reflect.Ptr {
+		return v.Elem()
+	}
+	return v
+}
+
+// elemInterface returns an interface value of a collection element (pointers are dereferenced)
+func elemInterface(elem reflect.Value) interface{} {
+	if elem = derefValue(elem); !elem.IsValid() {
+		return nil
+	}
+	return elem.Interface()
+}
+
+// addedInterface sets Added status for an added mutable object and returns its interface value
+func addedInterface(v reflect.Value) interface{} {
+	setMutableStatus(derefValue(v), Added)
+	return elemInterface(v)
+}
+
+// removedInterface returns an interface value of a removed object copy.
+// Copy of a mutable object has Removed status
+func removedInterface(v reflect.Value) interface{} {
+	if v = derefValue(v); !v.IsValid() {
+		return nil
+	}
+	removed := reflect.New(v.Type()).Elem()
+	removed.Set(v)
+	setMutableStatus(removed, Removed)
+	return removed.Interface()
+}
@@ -0,0 +1,148 @@
+package mutable
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type TestD struct {
+	Mutable
+	ID     int    `json:"id"`
+	FieldA string `json:"field_a"`
+}
+
+func TestLCS(t *testing.T) {
+	a := []string{"a", "b", "c", "d", "e"}
+	b := []string{"x", "b", "d", "y", "e"}
+	matches := lcs(len(a), len(b), func(i, j int) bool {
+		return a[i] == b[j]
+	})
+	assert.Equal(t, [][2]int{{1, 1}, {3, 2}, {4, 4}}, matches)
+	assert.Empty(t, lcs(0, 2, func(i, j int) bool { return true }))
+}
+
+func TestMutable_AnalyzeChanges_slice(t *testing.T) {
+	tst := &struct {
+		Mutable
+		FieldA []*TestD
+		FieldB []*TestD `mutable:"key=ID"`
+		FieldC []*TestD `mutable:"key=id"`
+	}{
+		FieldA: []*TestD{&TestD{ID: 1}, &TestD{ID: 2}},
+		FieldB: []*TestD{&TestD{ID: 1}, &TestD{ID: 2}, &TestD{ID: 3}},
+		FieldC: []*TestD{&TestD{ID: 1}, &TestD{ID: 2}},
+	}
+	tst.ResetMutableState(tst)
+	// Insert an element at the front
+	tst.FieldA = append([]*TestD{&TestD{ID: 0}}, tst.FieldA...)
+	// Move, change, remove and add elements
+	tst.FieldB = []*TestD{tst.FieldB[2], tst.FieldB[0], &TestD{ID: 4}}
+	tst.FieldB[1].FieldA = "apple"
+	// Duplicate keys fall back to LCS analyze
+	tst.FieldC[1].ID = 1
+	changes := tst.AnalyzeChanges()
+
+	fieldA := changes.GetField("FieldA")
+	if assert.NotNil(t, fieldA) {
+		assert.Len(t, fieldA.NestedFields, 1)
+		if added := fieldA.NestedFields.GetField("0"); assert.NotNil(t, added) {
+			assert.Equal(t, Added, added.Status)
+			assert.Equal(t, 0, added.NewIndex)
+		}
+	}
+
+	fieldB := changes.GetField("FieldB")
+	if assert.NotNil(t, fieldB) {
+		assert.Len(t, fieldB.NestedFields, 4)
+		if moved := fieldB.NestedFields.GetField("3"); assert.NotNil(t, moved) {
+			assert.Equal(t, Moved, moved.Status)
+			assert.Equal(t, 2, moved.OldIndex)
+			assert.Equal(t, 0, moved.NewIndex)
+		}
+		if changed := fieldB.NestedFields.GetField("1"); assert.NotNil(t, changed) {
+			assert.Equal(t, Changed, changed.Status)
+			assert.True(t, changed.NestedFields.Contains("FieldA"))
+		}
+		if removed := fieldB.NestedFields.GetField("2"); assert.NotNil(t, removed) {
+			assert.Equal(t, Removed, removed.Status)
+			assert.Equal(t, 1, removed.OldIndex)
+		}
+		if added := fieldB.NestedFields.GetField("4"); assert.NotNil(t, added) {
+			assert.Equal(t, Added, added.Status)
+			assert.Equal(t, 2, added.NewIndex)
+		}
+	}
+
+	fieldC := changes.GetField("FieldC")
+	if assert.NotNil(t, fieldC) {
+		assert.Len(t, fieldC.NestedFields, 1)
+		if changed := fieldC.NestedFields.GetField("1"); assert.NotNil(t, changed) {
+			assert.Equal(t, Changed, changed.Status)
+			assert.True(t, changed.NestedFields.Contains("ID"))
+		}
+	}
+}
+
+func TestMutable_AnalyzeChanges_sliceReplaced(t *testing.T) {
+	tst := &struct {
+		Mutable
+		FieldA []TestD
+	}{
+		FieldA: []TestD{TestD{ID: 1}, TestD{ID: 2}, TestD{ID: 3}},
+	}
+	tst.ResetMutableState(tst)
+	tst.FieldA = []TestD{TestD{ID: 3}, TestD{ID: 4}, TestD{ID: 5}}
+	changes := tst.AnalyzeChanges()
+	fieldA := changes.GetField("FieldA")
+	if assert.NotNil(t, fieldA) {
+		assert.Len(t, fieldA.NestedFields, 3)
+		assert.Equal(t, Removed, fieldA.NestedFields.GetField("0").Status)
+		assert.Equal(t, Added, fieldA.NestedFields.GetField("2").Status)
+		replaced := fieldA.NestedFields.GetField("1")
+		assert.Equal(t, Changed, replaced.Status)
+		assert.Equal(t, 2, replaced.OldValue.(TestD).ID)
+		assert.Equal(t, 4, replaced.NewValue.(TestD).ID)
+	}
+}
+
+func TestMutable_AnalyzeChanges_nilMap(t *testing.T) {
+	var tst = &TestE{}
+	assert.NoError(t, tst.ResetMutableState(tst))
+	tst.FieldF = map[string]string{}
+	changes := tst.AnalyzeChanges()
+	if assert.Contains(t, changes, "FieldF") {
+		assert.Nil(t, changes["FieldF"].OldValue)
+		assert.Equal(t, map[string]string{}, changes["FieldF"].NewValue)
+		assert.Equal(t, `[{"op":"add","path":"/field_f","value":{}}]`, string(marshal(changes.JSONPatch())))
+	}
+
+	assert.NoError(t, tst.ResetMutableState(tst))
+	tst.FieldF = nil
+	changes = tst.AnalyzeChanges()
+	if assert.Contains(t, changes, "FieldF") {
+		assert.Equal(t, map[string]string{}, changes["FieldF"].OldValue)
+		assert.Equal(t, `[{"op":"replace","path":"/field_f","value":null}]`, string(marshal(changes.JSONPatch())))
+	}
+	assert.NoError(t, tst.AcceptChanges(changes))
+	assert.Empty(t, tst.AnalyzeChangesForce())
+}
+
+func TestMutable_AnalyzeChanges_nilSlice(t *testing.T) {
+	var tst = &TestE{}
+	assert.NoError(t, tst.ResetMutableState(tst))
+	tst.FieldD = []*TestD{}
+	changes := tst.AnalyzeChanges()
+	if assert.Contains(t, changes, "FieldD") {
+		assert.Equal(t, []*TestD{}, changes["FieldD"].NewValue)
+		assert.Equal(t, `[{"op":"add","path":"/field_d","value":[]}]`, string(marshal(changes.JSONPatch())))
+	}
+
+	assert.NoError(t, tst.ResetMutableState(tst))
+	tst.FieldD = nil
+	changes = tst.AnalyzeChanges()
+	if assert.Contains(t, changes, "FieldD") {
+		assert.Equal(t, []*TestD{}, changes["FieldD"].OldValue)
+		assert.Equal(t, `[{"op":"replace","path":"/field_d","value":null}]`, string(marshal(changes.JSONPatch())))
+	}
+}
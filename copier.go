@@ -0,0 +1,150 @@
+package mutable
+
+import (
+	"reflect"
+
+	"github.com/go-ext/logger"
+)
+
+var (
+	// mutableType is a reflect type of Mutable struct
+	mutableType = reflect.TypeOf(Mutable{})
+	// clonerType is a reflect type of Cloner interface
+	clonerType = reflect.TypeOf((*Cloner)(nil)).Elem()
+)
+
+// copyKey identifies an already copied reference value
+type copyKey struct {
+	ptr    uintptr
+	length int
+	typ    reflect.Type
+}
+
+// copier makes deep copies of values.
+// It keeps track of already copied references to copy cyclic structures correctly
+type copier struct {
+	visited map[copyKey]reflect.Value
+}
+
+// deepCopy returns a deep copy of v.
+// Slices, maps, pointers, interfaces and nested structs are copied recursively. Unexported struct fields,
+// channels and functions are copied as is. Types implementing Cloner are copied with their own CloneForMutable method.
+// Mutable state of nested objects is not a part of a copy
+func deepCopy(v reflect.Value) reflect.Value {
+	c := &copier{visited: map[copyKey]reflect.Value{}}
+	return c.copy(v)
+}
+
+// copy returns a deep copy of v
+func (c *copier) copy(v reflect.Value) reflect.Value {
+	if !v.IsValid() {
+		return v
+	}
+	dst := reflect.New(v.Type()).Elem()
+	if clone, ok := c.copyWithCloner(v); ok {
+		dst.Set(clone)
+		return dst
+	}
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return dst
+		}
+		key := copyKey{ptr: v.Pointer(), typ: v.Type()}
+		if visited, ok := c.visited[key]; ok {
+			return visited
+		}
+		dst.Set(reflect.New(v.Type().Elem()))
+		// Register a copy before going down to handle cycles
+		c.visited[key] = dst
+		dst.Elem().Set(c.copy(v.Elem()))
+	case reflect.Interface:
+		if v.IsNil() {
+			return dst
+		}
+		dst.Set(c.copy(v.Elem()))
+	case reflect.Struct:
+		if v.Type() == mutableType {
+			// Mutable state is not copied
+			return dst
+		}
+		// Make a shallow copy first to keep unexported fields values
+		dst.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if !dst.Field(i).CanSet() {
+				// Unexported fields can't be copied deeply
+				continue
+			}
+			dst.Field(i).Set(c.copy(v.Field(i)))
+		}
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			dst.Index(i).Set(c.copy(v.Index(i)))
+		}
+	case reflect.Slice:
+		if v.IsNil() {
+			return dst
+		}
+		key := copyKey{ptr: v.Pointer(), length: v.Len(), typ: v.Type()}
+		if visited, ok := c.visited[key]; ok {
+			return visited
+		}
+		dst.Set(reflect.MakeSlice(v.Type(), v.Len(), v.Cap()))
+		c.visited[key] = dst
+		for i := 0; i < v.Len(); i++ {
+			dst.Index(i).Set(c.copy(v.Index(i)))
+		}
+	case reflect.Map:
+		if v.IsNil() {
+			return dst
+		}
+		key := copyKey{ptr: v.Pointer(), typ: v.Type()}
+		if visited, ok := c.visited[key]; ok {
+			return visited
+		}
+		dst.Set(reflect.MakeMapWithSize(v.Type(), v.Len()))
+		c.visited[key] = dst
+		for _, k := range v.MapKeys() {
+			// Keys are kept as is to save their identity
+			dst.SetMapIndex(k, c.copy(v.MapIndex(k)))
+		}
+	default:
+		// Basic kinds, channels, functions and unsafe pointers
+		dst.Set(v)
+	}
+	return dst
+}
+
+// copyWithCloner returns a copy of v made by its CloneForMutable method.
+// It reports false if v doesn't implement Cloner or its copy has an unexpected type
+func (c *copier) copyWithCloner(v reflect.Value) (reflect.Value, bool) {
+	if !v.CanInterface() {
+		return reflect.Value{}, false
+	}
+	var cloner Cloner
+	switch {
+	case v.Kind() == reflect.Ptr && v.IsNil(), v.Kind() == reflect.Interface:
+		// Nil pointers are copied as is, interfaces are handled by their underlying values
+		return reflect.Value{}, false
+	case v.Type().Implements(clonerType):
+		cloner = v.Interface().(Cloner)
+	case v.Kind() != reflect.Ptr && reflect.PtrTo(v.Type()).Implements(clonerType):
+		// CloneForMutable has a pointer receiver
+		ptr := reflect.New(v.Type())
+		ptr.Elem().Set(v)
+		cloner = ptr.Interface().(Cloner)
+	default:
+		return reflect.Value{}, false
+	}
+	clone := reflect.ValueOf(cloner.CloneForMutable())
+	switch {
+	case !clone.IsValid():
+		return reflect.Zero(v.Type()), true
+	case clone.Type().AssignableTo(v.Type()):
+		return clone, true
+	case clone.Kind() == reflect.Ptr && clone.Type().Elem().AssignableTo(v.Type()) && !clone.IsNil():
+		return clone.Elem(), true
+	}
+	logger.Warningf("unexpected CloneForMutable result type: %s (expected: %s)", clone.Type(), v.Type())
+	return reflect.Value{}, false
+}
@@ -0,0 +1,87 @@
+package mutable
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testCyclic struct {
+	Name string
+	Next *testCyclic
+}
+
+func TestDeepCopy(t *testing.T) {
+	var src = TestA{
+		FieldA: "one",
+		FieldE: &TestB{FieldA: "two", FieldB: []int{1, 2}},
+		FieldG: []*TestC{&TestC{FieldA: "three"}},
+		FieldH: map[string]*TestC{"one": &TestC{FieldB: []int{3}}},
+		FieldK: []int{4, 5},
+	}
+	src.ResetMutableState(&src)
+	dst := deepCopy(reflect.ValueOf(src)).Interface().(TestA)
+	assert.Equal(t, src.FieldA, dst.FieldA)
+	assert.Equal(t, *src.FieldE, *dst.FieldE)
+	assert.Equal(t, src.FieldK, dst.FieldK)
+	// Mutable state shouldn't be copied
+	assert.Nil(t, dst.target)
+	assert.Nil(t, dst.originalState)
+
+	// Copies shouldn't share memory with a source
+	src.FieldE.FieldB[0] = 10
+	src.FieldG[0].FieldA = "four"
+	src.FieldH["one"].FieldB[0] = 30
+	src.FieldK[1] = 50
+	assert.Equal(t, []int{1, 2}, dst.FieldE.FieldB)
+	assert.Equal(t, "three", dst.FieldG[0].FieldA)
+	assert.Equal(t, []int{3}, dst.FieldH["one"].FieldB)
+	assert.Equal(t, []int{4, 5}, dst.FieldK)
+}
+
+func TestDeepCopy_cyclic(t *testing.T) {
+	var src = &testCyclic{Name: "one"}
+	src.Next = &testCyclic{Name: "two", Next: src}
+	dst := deepCopy(reflect.ValueOf(src)).Interface().(*testCyclic)
+	assert.False(t, src == dst, "copy pointer")
+	assert.Equal(t, "two", dst.Next.Name)
+	assert.True(t, dst.Next.Next == dst, "cycle pointer")
+	assert.True(t, isEqual(reflect.ValueOf(src), reflect.ValueOf(dst)), "equal")
+}
+
+// testOpaque has unexported state which can't be copied by reflection
+type testOpaque struct {
+	values map[string]int
+}
+
+func (o *testOpaque) CloneForMutable() interface{} {
+	clone := &testOpaque{values: make(map[string]int, len(o.values))}
+	for k, v := range o.values {
+		clone.values[k] = v
+	}
+	return clone
+}
+
+func (o testOpaque) Equal(v interface{}) bool {
+	return reflect.DeepEqual(o.values, v.(testOpaque).values)
+}
+
+func TestDeepCopy_cloner(t *testing.T) {
+	tst := &struct {
+		Mutable
+		FieldA testOpaque
+		FieldB *testOpaque
+	}{
+		FieldA: testOpaque{values: map[string]int{"one": 1}},
+		FieldB: &testOpaque{values: map[string]int{"two": 2}},
+	}
+	tst.ResetMutableState(tst)
+	tst.FieldA.values["one"] = 10
+	tst.FieldB.values["two"] = 20
+	changes := tst.AnalyzeChanges()
+	assert.True(t, changes.Contains("FieldA"), "value with pointer receiver")
+	assert.True(t, changes.Contains("FieldB"), "pointer")
+	assert.Equal(t, 1, changes.GetField("FieldA").OldValue.(testOpaque).values["one"])
+	assert.Equal(t, 2, changes.GetField("FieldB").OldValue.(testOpaque).values["two"])
+}
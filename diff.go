@@ -0,0 +1,94 @@
+package mutable
+
+import (
+	"reflect"
+)
+
+// DiffOption is an option of Diff function
+type DiffOption func(*diffOptions)
+
+// diffOptions contains options of Diff function
+type diffOptions struct {
+	ignored []string   // Paths of ignored fields
+	syntax  PathSyntax // Syntax of paths of ignored fields (LevelSeparator separated paths if it's nil)
+}
+
+// IgnoreFields returns an option to exclude fields with given paths from Diff results.
+// Paths consist of field names or JSON names as they are stated in ChangedFields separated by LevelSeparator
+// (eg. FieldA/FieldZ or field_a/field_z) unless another syntax is set with UsePathSyntax
+func IgnoreFields(paths ...string) DiffOption {
+	return func(o *diffOptions) {
+		o.ignored = append(o.ignored, paths...)
+	}
+}
+
+// UsePathSyntax returns an option to parse paths of ignored fields with a syntax instead of LevelSeparator separated ones
+func UsePathSyntax(syntax PathSyntax) DiffOption {
+	return func(o *diffOptions) {
+		o.syntax = syntax
+	}
+}
+
+// Diff returns changed fields of a new value compared to an old one.
+// Both values must be structs or pointers to structs of the same type, struct tags are honoured as for Mutable.
+// Diff has no side effects, so mutable statuses of given values and their nested objects are kept as is
+func Diff(old, new interface{}, opts ...DiffOption) (ChangedFields, error) {
+	var options diffOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	oldValue, newValue := derefValue(reflect.ValueOf(old)), derefValue(reflect.ValueOf(new))
+	if !oldValue.IsValid() || !newValue.IsValid() {
+		return nil, errNilValue
+	}
+	if oldValue.Type() != newValue.Type() {
+		return nil, errTypeMismatch(oldValue.Type(), newValue.Type())
+	}
+	if newValue.Kind() != reflect.Struct {
+		return nil, errNotStruct
+	}
+	changedFields := diffValues(newValue, oldValue)
+	syntax := pathOptions{syntax: options.syntax}.pathSyntax()
+	for _, path := range options.ignored {
+		if names := levelNames(syntax, path); len(names) > 0 {
+			changedFields.remove(names)
+		}
+	}
+	return changedFields, nil
+}
+
+// diffValues returns changed fields of a current struct value compared to an original one.
+// A deep copy of a current value is analyzed to never update mutable states of a given one
+func diffValues(current, original reflect.Value) ChangedFields {
+	return tryAnalyzeChanges(deepCopy(current), original)
+}
+
+// remove removes a changed field with a given path from c.
+// Parent fields having no nested changes left are removed as well
+func (c ChangedFields) remove(path []string) {
+	key, ok := c.fieldKey(path[0])
+	if !ok {
+		return
+	}
+	if len(path) > 1 {
+		field := c[key]
+		field.NestedFields.remove(path[1:])
+		if len(field.NestedFields) > 0 {
+			return
+		}
+	}
+	delete(c, key)
+}
+
+// fieldKey returns a key of a changed field within c matched by its name or JSON name
+func (c ChangedFields) fieldKey(name string) (string, bool) {
+	if _, ok := c[name]; ok {
+		return name, true
+	}
+	for key, field := range c {
+		if len(field.JSONName) > 0 && field.JSONName == name {
+			return key, true
+		}
+	}
+	return "", false
+}
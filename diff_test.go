@@ -0,0 +1,65 @@
+package mutable
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	var old = TestA{
+		FieldA: "one",
+		FieldD: TestB{FieldA: "a"},
+		FieldG: []*TestC{{FieldA: "a"}},
+	}
+	var current = TestA{
+		FieldA: "two",
+		FieldD: TestB{FieldA: "b", FieldB: []int{1}},
+		FieldG: []*TestC{{FieldA: "a"}, {FieldA: "b"}},
+	}
+	changes, err := Diff(old, &current)
+	if assert.NoError(t, err) {
+		assert.Len(t, changes, 3)
+		assert.Equal(t, "one", changes["FieldA"].OldValue)
+		assert.Equal(t, "two", changes["FieldA"].NewValue)
+		assert.Len(t, changes["FieldD"].NestedFields, 2)
+		assert.Equal(t, Added, changes["FieldG"].NestedFields["1"].Status)
+	}
+	// No side effects
+	assert.Equal(t, NotChanged, current.FieldG[1].MutableStatus)
+	assert.Equal(t, NotChanged, current.MutableStatus)
+	assert.Empty(t, current.ChangedFields)
+
+	// Ignored fields
+	changes, err = Diff(&old, &current, IgnoreFields("FieldA", "FieldD/FieldA", "FieldG/1"))
+	if assert.NoError(t, err) {
+		assert.Len(t, changes, 1)
+		assert.Equal(t, []int{1}, changes["FieldD"].NestedFields["FieldB"].NewValue)
+	}
+	changes, err = Diff(&old, &current, IgnoreFields("FieldD/FieldA", "FieldD/FieldB", "Unknown/Field"))
+	if assert.NoError(t, err) {
+		assert.Len(t, changes, 2)
+		assert.False(t, changes.Contains("FieldD"))
+	}
+	// Ignored fields by JSON names
+	changes, err = Diff(&old, &current, IgnoreFields("field_a", "field_d/field_a", "FieldG/1"))
+	if assert.NoError(t, err) {
+		assert.Len(t, changes, 1)
+		assert.Len(t, changes["FieldD"].NestedFields, 1)
+	}
+
+	// Equal values
+	changes, err = Diff(old, old)
+	assert.NoError(t, err)
+	assert.Empty(t, changes)
+
+	// Invalid values
+	_, err = Diff(old, TestB{})
+	assert.Error(t, err)
+	_, err = Diff(nil, &current)
+	assert.Error(t, err)
+	_, err = Diff((*TestA)(nil), &current)
+	assert.Error(t, err)
+	_, err = Diff(1, 2)
+	assert.Error(t, err)
+}
@@ -1,7 +1,121 @@
 package mutable
 
+import (
+	"reflect"
+)
+
 // Equaler is the interface that wraps custom Equal function allowing to check differences
 // between two objects of the same type
 type Equaler interface {
 	Equal(interface{}) bool
 }
+
+// equalerType is a reflect type of Equaler interface
+var equalerType = reflect.TypeOf((*Equaler)(nil)).Elem()
+
+// visitPair identifies a pair of already compared reference values
+type visitPair struct {
+	a, b uintptr
+	typ  reflect.Type
+}
+
+// comparer compares values deeply.
+// It keeps track of already compared references to compare cyclic structures correctly
+type comparer struct {
+	visited map[visitPair]bool
+}
+
+// isEqual reports whether a and b are deeply equal.
+// Unlike reflect.DeepEqual it uses Equal method of types implementing Equaler and ignores Mutable state
+// of nested objects
+func isEqual(a, b reflect.Value) bool {
+	c := &comparer{visited: map[visitPair]bool{}}
+	return c.equal(a, b)
+}
+
+// equal reports whether a and b are deeply equal
+func (c *comparer) equal(a, b reflect.Value) bool {
+	if !a.IsValid() || !b.IsValid() {
+		return a.IsValid() == b.IsValid()
+	}
+	if a.Type() != b.Type() {
+		return false
+	}
+	// Check nil values first to avoid Equal method calls on nil receivers
+	switch a.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface, reflect.Func, reflect.Chan:
+		if a.IsNil() || b.IsNil() {
+			return a.IsNil() == b.IsNil()
+		}
+	}
+	if a.CanInterface() && b.CanInterface() && a.Type().Implements(equalerType) {
+		// Compare with type's Equal method
+		return a.Interface().(Equaler).Equal(b.Interface())
+	}
+	switch a.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice:
+		if a.Pointer() == b.Pointer() && (a.Kind() != reflect.Slice || a.Len() == b.Len()) {
+			return true
+		}
+		pair := visitPair{a: a.Pointer(), b: b.Pointer(), typ: a.Type()}
+		if c.visited[pair] {
+			// Already compared or being compared
+			return true
+		}
+		c.visited[pair] = true
+	}
+	switch a.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return c.equal(a.Elem(), b.Elem())
+	case reflect.Struct:
+		for i := 0; i < a.NumField(); i++ {
+			if a.Type().Field(i).Type == mutableType {
+				// Pass through Mutable state
+				continue
+			}
+			if !c.equal(a.Field(i), b.Field(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Slice, reflect.Array:
+		if a.Len() != b.Len() {
+			return false
+		}
+		for i := 0; i < a.Len(); i++ {
+			if !c.equal(a.Index(i), b.Index(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Map:
+		if a.Len() != b.Len() {
+			return false
+		}
+		for _, key := range a.MapKeys() {
+			bValue := b.MapIndex(key)
+			if !bValue.IsValid() || !c.equal(a.MapIndex(key), bValue) {
+				return false
+			}
+		}
+		return true
+	case reflect.Bool:
+		return a.Bool() == b.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return a.Int() == b.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return a.Uint() == b.Uint()
+	case reflect.Float32, reflect.Float64:
+		return a.Float() == b.Float()
+	case reflect.Complex64, reflect.Complex128:
+		return a.Complex() == b.Complex()
+	case reflect.String:
+		return a.String() == b.String()
+	case reflect.Func:
+		// Non-nil functions are never equal
+		return false
+	default:
+		// Channels and unsafe pointers
+		return a.Pointer() == b.Pointer()
+	}
+}
@@ -22,7 +22,49 @@ var (
 	errUnsupportedType  = func(fieldType reflect.Type, value interface{}) error {
 		return fmt.Errorf("unsupported value type (%T) for a field (%v)", value, fieldType)
 	}
-	errNotJSON = errors.New("not a valid JSON value")
+	errNotJSON      = errors.New("not a valid JSON value")
+	errNotStruct    = errors.New("given value is not a Struct type")
+	errNilValue     = errors.New("given value is nil")
+	errTypeMismatch = func(a, b reflect.Type) error {
+		return fmt.Errorf("types mismatch (%v and %v)", a, b)
+	}
+	errNoTarget        = errors.New("mutable state is not initialized, ResetMutableState must be called first")
+	errNilPointer      = errors.New("nil pointer within a path")
+	errNilMap          = errors.New("nil map within a path")
+	errPathNotFound    = errors.New("path not found")
+	errIndexOutOfRange = func(token string) error {
+		return fmt.Errorf("index out of range (%v)", token)
+	}
+	errInvalidMapKey = func(token string) error {
+		return fmt.Errorf("invalid map key (%v)", token)
+	}
+	errInvalidPath = func(path string) error {
+		return fmt.Errorf("invalid path (%v)", path)
+	}
+	errInvalidPointer = func(pointer string) error {
+		return fmt.Errorf("invalid JSON Pointer (%v)", pointer)
+	}
+	errInvalidPatch = func(err error) error {
+		return fmt.Errorf("invalid JSON Patch document: %w", err)
+	}
+	errPatchOperation = func(i int, op, path string, err error) error {
+		return fmt.Errorf("cannot apply patch operation #%d (%v %v): %w", i, op, path, err)
+	}
+	errUnsupportedOp = func(op string) error {
+		return fmt.Errorf("unsupported operation (%v)", op)
+	}
+	errCannotSetPath = func(path string, err error) error {
+		return fmt.Errorf("cannot set a value to the path (%v): %w", path, err)
+	}
+	errNoCheckpoint = func(name string) error {
+		return fmt.Errorf("checkpoint not found (%v)", name)
+	}
+	errNothingToUndo = errors.New("nothing to undo")
+	errNothingToRedo = errors.New("nothing to redo")
+	errGroupOpen     = errors.New("history group is not ended")
+	errMissingValue  = errors.New("operation value is missing")
+	errMoveToChild   = errors.New("cannot move a value to its own child")
+	errTestFailed    = errors.New("test operation failed")
 )
 
 // IsCannotSetErr reports whether an err is a errCannotSetValue error
@@ -0,0 +1,93 @@
+package mutable
+
+import (
+	"reflect"
+
+	"github.com/go-ext/logger"
+)
+
+// This file contains support of code generated by cmd/mutablegen.
+// Exported functions below are called by generated methods and are not intended to be used directly
+
+// FieldSetter sets a value to a field by its name. It reports false if a field is not handled by a setter
+type FieldSetter func(fieldName string, value interface{}) (bool, error)
+
+// Analyzer returns changed fields of an object compared to a given original state
+type Analyzer func(originalState interface{}) ChangedFields
+
+// GenReset resets mutable state m of self object with a given snapshot of its original state
+func GenReset(m *Mutable, self, snapshot interface{}) error {
+	if reflect.ValueOf(self).Kind() != reflect.Ptr {
+		return errNotPointer
+	}
+	m.target = self
+	m.MutableStatus = NotChanged
+	m.originalState = snapshot
+	m.ChangedFields = ChangedFields{}
+	m.analyzed = false
+	m.observers.reset()
+	return nil
+}
+
+// GenResetNested resets mutable state of a nested mutable object or mutable collection elements field points to
+func GenResetNested(field interface{}) error {
+	return resetNestedMutable(reflect.ValueOf(field).Elem())
+}
+
+// GenSetValue sets a value for given field by its name with a setter, fields not handled by a setter are set
+// with reflection. A setter handles LevelSeparator separated paths only, so it's not used with another path syntax
+func GenSetValue(m *Mutable, fieldName string, value interface{}, setter FieldSetter) error {
+	if m.paths.syntax != nil {
+		setter = nil
+	}
+	return m.setValue(fieldName, value, setter)
+}
+
+// GenInvalidate invalidates analyzed changes of a nested mutable object a field has been set within
+func GenInvalidate(m *Mutable) {
+	m.analyzed = false
+}
+
+// GenParse sets a value to a field dst points to (see SetValue for supported values)
+func GenParse(fieldName string, value, dst interface{}) error {
+	if err := trySetValueToField(reflect.ValueOf(dst).Elem(), value); err != nil {
+		logger.Warningf("Error: %s, Field: %s", err, fieldName)
+		return errCannotSetValue(fieldName, value)
+	}
+	return nil
+}
+
+// GenAnalyzeChanges returns changed fields of m target object analyzed with analyzer.
+// Cached changes are returned unless force is true
+func GenAnalyzeChanges(m *Mutable, force bool, analyzer Analyzer) ChangedFields {
+	if m.analyzed && !force {
+		return m.ChangedFields
+	}
+	return m.analyzeChanges(analyzer)
+}
+
+// GenAnalyzeField analyzes changes of a struct field with reflection.
+// current and original are pointers to the field of current and original objects, tag is the field's tag
+func GenAnalyzeField(name, tag string, current, original interface{}) *ChangedField {
+	meta := &fieldInfo{
+		name:     name,
+		jsonName: jsonName(reflect.StructField{Name: name, Tag: reflect.StructTag(tag)}),
+		options:  parseTagOptions(reflect.StructTag(tag)),
+	}
+	changedField := analyzeField(meta, reflect.ValueOf(current).Elem(), reflect.ValueOf(original).Elem())
+	if changedField != nil {
+		changedField.JSONName = meta.jsonName
+	}
+	return changedField
+}
+
+// GenUpdateState sets changed fields data and an appropriate status of a nested mutable object
+func GenUpdateState(m *Mutable, changedFields ChangedFields) {
+	m.updateState(changedFields)
+}
+
+// GenDeepCopy replaces a value field points to with its deep copy
+func GenDeepCopy(field interface{}) {
+	v := reflect.ValueOf(field).Elem()
+	v.Set(deepCopy(v))
+}
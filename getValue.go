@@ -0,0 +1,39 @@
+package mutable
+
+import (
+	"reflect"
+)
+
+// GetValue returns a value of a target object field by its path (see SetValue for supported paths).
+// A value is returned as it is, so slices, maps and pointers are shared with a target object
+func (m *Mutable) GetValue(fieldName string) (interface{}, error) {
+	if m.target == nil {
+		return nil, errNoTarget
+	}
+	return getValue(reflect.ValueOf(m.target).Elem(), fieldName, m.paths, false)
+}
+
+// GetOriginalValue returns a value of a field within an original state by its path (see SetValue for supported paths).
+// A deep copy of a value is returned, so an original state can't be modified with it
+func (m *Mutable) GetOriginalValue(fieldName string) (interface{}, error) {
+	if m.target == nil {
+		return nil, errNoTarget
+	}
+	return getValue(m.original(), fieldName, m.paths, true)
+}
+
+// getValue returns a value of a field of v by its path, a deep copy of a value if copied is true
+func getValue(v reflect.Value, fieldName string, paths pathOptions, copied bool) (interface{}, error) {
+	tokens, ok := paths.splitExisting(v, fieldName)
+	if !ok {
+		return nil, errCannotFind(fieldName)
+	}
+	value, err := lookupPath(v, tokens)
+	if err != nil || !value.CanInterface() {
+		return nil, errCannotFind(fieldName)
+	}
+	if copied {
+		value = deepCopy(value)
+	}
+	return value.Interface(), nil
+}
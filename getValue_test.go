@@ -0,0 +1,56 @@
+package mutable
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMutable_GetValue(t *testing.T) {
+	var tst = &TestG{
+		FieldA: []*TestC{{FieldA: "a"}},
+		FieldB: map[string]*TestC{"one": {FieldB: []int{1}}},
+		FieldG: map[int]string{1: "a"},
+		FieldH: map[string]string{"a/b": "c"},
+	}
+	_, err := tst.GetValue("field_a")
+	assert.Equal(t, errNoTarget, err)
+	assert.NoError(t, tst.ResetMutableState(tst))
+
+	assert.NoError(t, tst.SetValue("field_a/0/field_a", "b"))
+	assert.NoError(t, tst.SetValue("field_g/2", "b"))
+	for path, expected := range map[string][2]interface{}{
+		"field_a/0/field_a":   {"b", "a"},
+		"field_b/one/field_b": {[]int{1}, []int{1}},
+		"field_g/1":           {"a", "a"},
+		"field_h/a/b":         {"c", "c"},
+		"field_e":             {[]int(nil), []int(nil)},
+	} {
+		value, err := tst.GetValue(path)
+		if assert.NoError(t, err, path) {
+			assert.Equal(t, expected[0], value, path)
+		}
+		value, err = tst.GetOriginalValue(path)
+		if assert.NoError(t, err, path) {
+			assert.Equal(t, expected[1], value, path)
+		}
+	}
+
+	// An added element doesn't exist within an original state
+	value, err := tst.GetValue("field_g/2")
+	assert.NoError(t, err)
+	assert.Equal(t, "b", value)
+	_, err = tst.GetOriginalValue("field_g/2")
+	assert.True(t, IsCannotFindErr(err))
+
+	// An original value is a deep copy
+	value, _ = tst.GetOriginalValue("field_b/one/field_b")
+	value.([]int)[0] = 2
+	value, _ = tst.GetOriginalValue("field_b/one/field_b")
+	assert.Equal(t, []int{1}, value)
+
+	for _, path := range []string{"unknown", "field_a/1", "field_a/0/unknown", "field_c/0/field_a", "field_g/x"} {
+		_, err := tst.GetValue(path)
+		assert.True(t, IsCannotFindErr(err), path)
+	}
+}
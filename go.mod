@@ -1,5 +1,7 @@
 module github.com/askretov/mutable
 
+go 1.18
+
 require (
 	github.com/askretov/ansi v0.0.0-20150914162238-c286dcecd19f // indirect
 	github.com/go-ext/logger v1.0.1
@@ -0,0 +1,164 @@
+package mutable
+
+import (
+	"reflect"
+)
+
+// HistoryRecord is a change of a field made with SetValue
+type HistoryRecord struct {
+	FieldName string        `json:"field_name"` // Field name as it was given to SetValue
+	OldValue  interface{}   `json:"old_value"`  // Value of a field before a change
+	NewValue  interface{}   `json:"new_value"`  // Value of a field after a change
+	tokens    []string      // Resolved path of a field
+	oldValue  reflect.Value // Old value keeping its type even if it's nil
+	newValue  reflect.Value // New value keeping its type even if it's nil
+}
+
+// HistoryEntry is an undoable step: changes of a single SetValue call or of a group of calls
+type HistoryEntry []HistoryRecord
+
+// history keeps undo and redo stacks of SetValue changes
+type history struct {
+	depth  int            // Max number of undoable entries (unlimited if it's not positive)
+	undo   []HistoryEntry // Undoable entries, the latest is the last one
+	redo   []HistoryEntry // Redoable entries, the latest undone is the last one
+	group  HistoryEntry   // Records of an open group
+	groups int            // Nesting level of open groups
+}
+
+// EnableHistory enables recording of SetValue changes to undo and redo them.
+// Depth is a max number of undoable entries, older entries are dropped (unlimited if depth is not positive).
+// Existing history is cleared
+func (m *Mutable) EnableHistory(depth int) {
+	m.history = &history{depth: depth}
+}
+
+// DisableHistory disables recording of SetValue changes and clears history
+func (m *Mutable) DisableHistory() {
+	m.history = nil
+}
+
+// BeginGroup starts a group of SetValue changes which are undone and redone as a single entry.
+// Groups may be nested, a group is recorded on the outermost EndGroup call
+func (m *Mutable) BeginGroup() {
+	if m.history != nil {
+		m.history.groups++
+	}
+}
+
+// EndGroup ends a group of SetValue changes started with BeginGroup
+func (m *Mutable) EndGroup() {
+	h := m.history
+	if h == nil || h.groups == 0 {
+		return
+	}
+	if h.groups--; h.groups == 0 && len(h.group) > 0 {
+		h.push(h.group)
+		h.group = nil
+	}
+}
+
+// CanUndo reports whether there is an entry to undo
+func (m *Mutable) CanUndo() bool {
+	return m.history != nil && len(m.history.undo) > 0
+}
+
+// CanRedo reports whether there is an entry to redo
+func (m *Mutable) CanRedo() bool {
+	return m.history != nil && len(m.history.redo) > 0
+}
+
+// History returns undoable entries, the latest is the last one
+func (m *Mutable) History() []HistoryEntry {
+	if m.history == nil {
+		return nil
+	}
+	return append([]HistoryEntry{}, m.history.undo...)
+}
+
+// Undo restores old values of fields changed by the latest history entry
+func (m *Mutable) Undo() error {
+	if !m.CanUndo() {
+		return errNothingToUndo
+	}
+	if m.history.groups > 0 {
+		return errGroupOpen
+	}
+	h := m.history
+	entry := h.undo[len(h.undo)-1]
+	for i := len(entry) - 1; i >= 0; i-- {
+		if err := m.restoreRecord(entry[i], entry[i].oldValue); err != nil {
+			return err
+		}
+	}
+	h.undo = h.undo[:len(h.undo)-1]
+	h.redo = append(h.redo, entry)
+	return nil
+}
+
+// Redo sets new values of fields changed by the latest undone history entry
+func (m *Mutable) Redo() error {
+	if !m.CanRedo() {
+		return errNothingToRedo
+	}
+	if m.history.groups > 0 {
+		return errGroupOpen
+	}
+	h := m.history
+	entry := h.redo[len(h.redo)-1]
+	for _, record := range entry {
+		if err := m.restoreRecord(record, record.newValue); err != nil {
+			return err
+		}
+	}
+	h.redo = h.redo[:len(h.redo)-1]
+	h.undo = append(h.undo, entry)
+	return nil
+}
+
+// restoreRecord sets a value to a field of a history record
+func (m *Mutable) restoreRecord(record HistoryRecord, value reflect.Value) error {
+	if err := walkPath(reflect.ValueOf(m.target).Elem(), record.tokens, restorePathValue(value)); err != nil {
+		return errCannotSetPath(record.FieldName, err)
+	}
+	// Invalidate analyzed changes of a target object and nested objects along a path
+	m.analyzed = false
+	invalidatePath(reflect.ValueOf(m.target).Elem(), record.tokens)
+	return nil
+}
+
+// fieldValue returns resolved path tokens and a deep copy of a target field value.
+// If a field doesn't exist yet (see SetValue for allocated levels of a path), the nearest existing level of a path
+// is returned instead. It returns an invalid value if a path can't be resolved
+func (m *Mutable) fieldValue(fieldName string) ([]string, reflect.Value) {
+	target := reflect.ValueOf(m.target).Elem()
+	tokens, ok := m.paths.split(target, fieldName)
+	if !ok {
+		return nil, reflect.Value{}
+	}
+	for n := len(tokens); n > 0; n-- {
+		if value, err := pathValue(target, tokens[:n]); err == nil {
+			return tokens[:n], value
+		}
+	}
+	return nil, reflect.Value{}
+}
+
+// record adds a change of a field to history
+func (h *history) record(record HistoryRecord) {
+	// A new change makes undone entries not redoable
+	h.redo = nil
+	if h.groups > 0 {
+		h.group = append(h.group, record)
+		return
+	}
+	h.push(HistoryEntry{record})
+}
+
+// push adds an entry to the undo stack keeping its depth
+func (h *history) push(entry HistoryEntry) {
+	h.undo = append(h.undo, entry)
+	if h.depth > 0 && len(h.undo) > h.depth {
+		h.undo = append([]HistoryEntry{}, h.undo[len(h.undo)-h.depth:]...)
+	}
+}
@@ -0,0 +1,95 @@
+package mutable
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMutable_History(t *testing.T) {
+	var tst = &TestF{
+		FieldA: "one",
+		FieldC: &TestC{FieldA: "c"},
+	}
+	tst.ResetMutableState(tst)
+	// History is disabled by default
+	assert.NoError(t, tst.SetValue("field/a", "two"))
+	assert.False(t, tst.CanUndo())
+	assert.Error(t, tst.Undo())
+	assert.Nil(t, tst.History())
+
+	tst.EnableHistory(0)
+	assert.NoError(t, tst.SetValue("field/a", "three"))
+	assert.NoError(t, tst.SetValue("field/field_a", "a"))
+	assert.NoError(t, tst.SetValue("field_c", `{"field_a": "d"}`))
+	assert.Error(t, tst.SetValue("unknown", 1))
+	history := tst.History()
+	assert.Len(t, history, 3)
+	assert.Equal(t, HistoryRecord{FieldName: "field/a", OldValue: "two", NewValue: "three"}, HistoryRecord{
+		FieldName: history[0][0].FieldName,
+		OldValue:  history[0][0].OldValue,
+		NewValue:  history[0][0].NewValue,
+	})
+
+	// Undo
+	assert.NoError(t, tst.Undo())
+	assert.Equal(t, "c", tst.FieldC.FieldA)
+	assert.NoError(t, tst.FieldC.SetValue("field_a", "e"))
+	assert.NoError(t, tst.Undo())
+	assert.Empty(t, tst.FieldB.FieldA)
+	assert.True(t, tst.CanRedo())
+	assert.Equal(t, "three", tst.FieldA)
+	assert.Len(t, tst.AnalyzeChanges(), 2)
+
+	// Redo
+	assert.NoError(t, tst.Redo())
+	assert.Equal(t, "a", tst.FieldB.FieldA)
+	assert.NoError(t, tst.Redo())
+	assert.Equal(t, "d", tst.FieldC.FieldA)
+	assert.False(t, tst.CanRedo())
+	assert.Error(t, tst.Redo())
+
+	// A new change clears redo entries
+	assert.NoError(t, tst.Undo())
+	assert.NoError(t, tst.SetValue("field/a", "four"))
+	assert.False(t, tst.CanRedo())
+
+	// Groups
+	tst.BeginGroup()
+	assert.NoError(t, tst.SetValue("field/a", "five"))
+	tst.BeginGroup()
+	assert.NoError(t, tst.SetValue("field/field_a", "b"))
+	tst.EndGroup()
+	assert.Error(t, tst.Undo())
+	tst.EndGroup()
+	assert.Len(t, tst.History(), 4)
+	assert.Len(t, tst.History()[3], 2)
+	assert.NoError(t, tst.Undo())
+	assert.Equal(t, "four", tst.FieldA)
+	assert.Equal(t, "a", tst.FieldB.FieldA)
+
+	// Undo all
+	for tst.CanUndo() {
+		assert.NoError(t, tst.Undo())
+	}
+	assert.Equal(t, "two", tst.FieldA)
+	assert.Empty(t, tst.FieldB.FieldA)
+	assert.Equal(t, "c", tst.FieldC.FieldA)
+
+	tst.DisableHistory()
+	assert.False(t, tst.CanRedo())
+}
+
+func TestMutable_History_depth(t *testing.T) {
+	var tst = &TestF{}
+	tst.ResetMutableState(tst)
+	tst.EnableHistory(2)
+	for _, value := range []string{"a", "b", "c"} {
+		assert.NoError(t, tst.SetValue("field/a", value))
+	}
+	assert.Len(t, tst.History(), 2)
+	assert.NoError(t, tst.Undo())
+	assert.NoError(t, tst.Undo())
+	assert.Error(t, tst.Undo())
+	assert.Equal(t, "a", tst.FieldA)
+}
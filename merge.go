@@ -0,0 +1,148 @@
+package mutable
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+
+	"github.com/go-ext/logger"
+)
+
+// ApplyMergePatch applies a JSON Merge Patch document (RFC 7386) to a target object.
+// Members of a document are set to fields with the same JSON names (see SetValue for supported values),
+// nested objects are merged into nested structs and maps, null values clear fields and delete map keys.
+// A patch is applied atomically: if any member can't be set, a target object is left untouched
+func (m *Mutable) ApplyMergePatch(patch []byte) error {
+	if m.target == nil {
+		return errNoTarget
+	}
+	var document map[string]json.RawMessage
+	if err := json.Unmarshal(patch, &document); err != nil {
+		return errInvalidPatch(err)
+	}
+	target := reflect.ValueOf(m.target).Elem()
+	// Try to apply a patch to a deep copy of a target first
+	if err := applyMergePatch(deepCopy(target), nil, document); err != nil {
+		return err
+	}
+	if err := applyMergePatch(target, nil, document); err != nil {
+		return err
+	}
+	// Invalidate analyzed changes
+	m.analyzed = false
+	return nil
+}
+
+// applyMergePatch merges document members into an object with a given path within v
+func applyMergePatch(v reflect.Value, path []string, document map[string]json.RawMessage) error {
+	for name, raw := range document {
+		memberPath := append(path[:len(path):len(path)], name)
+		var err error
+		switch {
+		case isNullJSON(raw):
+			err = walkPath(v, memberPath, clearPathValue)
+		case isObjectJSON(raw) && isMergeable(v, memberPath):
+			var nested map[string]json.RawMessage
+			if err = json.Unmarshal(raw, &nested); err == nil {
+				err = applyMergePatch(v, memberPath, nested)
+			}
+		default:
+			err = walkPath(v, memberPath, setPathValue(jsonValue(raw), true))
+		}
+		if err != nil {
+			return errCannotSetPath(formatPointer(memberPath), err)
+		}
+		invalidatePath(v, memberPath)
+	}
+	return nil
+}
+
+// isMergeable reports whether an object document can be merged into an existing value with a given path within v.
+// Otherwise a value is replaced with a document
+func isMergeable(v reflect.Value, path []string) bool {
+	value, err := lookupPath(v, path)
+	if err != nil {
+		return false
+	}
+	value = derefValue(value)
+	switch value.Kind() {
+	case reflect.Struct:
+		return true
+	case reflect.Map:
+		return !value.IsNil()
+	}
+	return false
+}
+
+// clearPathValue sets a container element to its zero value or deletes a map key
+func clearPathValue(container reflect.Value, token string) error {
+	if container.Kind() == reflect.Map {
+		key, err := mapKey(container.Type().Key(), token)
+		if err != nil {
+			return err
+		}
+		container.SetMapIndex(key, reflect.Value{})
+		return nil
+	}
+	return removePathValue(container, token)
+}
+
+// isNullJSON reports whether raw is a JSON null value
+func isNullJSON(raw []byte) bool {
+	return bytes.Equal(bytes.TrimSpace(raw), []byte("null"))
+}
+
+// isObjectJSON reports whether raw is a JSON object
+func isObjectJSON(raw []byte) bool {
+	raw = bytes.TrimSpace(raw)
+	return len(raw) > 0 && raw[0] == '{'
+}
+
+// MergePatch returns a JSON Merge Patch document (RFC 7386) which transforms an original state into a current one.
+// Members are named by JSON names of struct fields and map keys, changed slices are replaced entirely
+func (c ChangedFields) MergePatch() []byte {
+	result, err := json.Marshal(c.mergePatch())
+	if err != nil {
+		logger.Error(err)
+	}
+	return result
+}
+
+// mergePatch returns a merge document of c struct fields changes
+func (c ChangedFields) mergePatch() map[string]interface{} {
+	document := make(map[string]interface{}, len(c))
+	for _, field := range c {
+		name := field.JSONName
+		if len(name) == 0 {
+			name = field.Name
+		}
+		document[name] = field.mergePatch()
+	}
+	return document
+}
+
+// mergePatch returns a merge document member of c changes
+func (c *ChangedField) mergePatch() interface{} {
+	switch {
+	case c.currentNil:
+		return nil
+	case c.kind == reflect.Slice:
+		return c.current
+	case c.kind == reflect.Map:
+		document := make(map[string]interface{}, len(c.NestedFields))
+		for key, elem := range c.NestedFields {
+			switch {
+			case elem.Status == Removed:
+				document[key] = nil
+			case elem.Status == Changed && len(elem.NestedFields) > 0:
+				document[key] = elem.NestedFields.mergePatch()
+			default:
+				document[key] = elem.NewValue
+			}
+		}
+		return document
+	case len(c.NestedFields) > 0:
+		return c.NestedFields.mergePatch()
+	}
+	return c.NewValue
+}
@@ -0,0 +1,126 @@
+package mutable
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMutable_ApplyMergePatch(t *testing.T) {
+	newTestE := func() *TestE {
+		tst := &TestE{
+			FieldA: "one",
+			FieldB: &TestB{FieldA: "a", FieldB: []int{1, 2}},
+			FieldD: []*TestD{{ID: 1}, {ID: 2}},
+			FieldF: map[string]string{"a": "1", "b": "2"},
+			FieldG: map[string]*TestC{"x": {FieldA: "x"}},
+		}
+		tst.ResetMutableState(tst)
+		return tst
+	}
+
+	tst := newTestE()
+	err := tst.ApplyMergePatch([]byte(`{
+		"field_a": "two",
+		"field_b": {"field_b": [3]},
+		"field/c": {"field_a": "c"},
+		"field_d": [{"id": 3}],
+		"field_e": [{"field_a": "e"}],
+		"field_f": {"a": null, "c": "3"},
+		"field_g": {"x": {"field_a": "y"}, "z": {"field_a": "z"}}
+	}`))
+	if assert.NoError(t, err) {
+		assert.Equal(t, "two", tst.FieldA)
+		assert.Equal(t, &TestB{FieldA: "a", FieldB: []int{3}}, tst.FieldB)
+		assert.Equal(t, "c", tst.FieldC.FieldA)
+		assert.Len(t, tst.FieldD, 1)
+		assert.Equal(t, 3, tst.FieldD[0].ID)
+		assert.Equal(t, "e", tst.FieldE[0].FieldA)
+		assert.Equal(t, map[string]string{"b": "2", "c": "3"}, tst.FieldF)
+		assert.Equal(t, "y", tst.FieldG["x"].FieldA)
+		assert.Equal(t, "z", tst.FieldG["z"].FieldA)
+	}
+
+	// Null values clear fields
+	tst = newTestE()
+	if assert.NoError(t, tst.ApplyMergePatch([]byte(`{"field_a": null, "field_b": null, "field_f": null}`))) {
+		assert.Empty(t, tst.FieldA)
+		assert.Nil(t, tst.FieldB)
+		assert.Nil(t, tst.FieldF)
+	}
+
+	// Failed patches leave an object untouched
+	for _, patch := range []string{
+		`{"field_a": "two", "unknown": 1}`,
+		`{"field_a": "two", "field_b": {"field_a": {"a": 1}}}`,
+		`{"field_a": "two", "field_d": {"id": 1}}`,
+		`[]`,
+	} {
+		tst := newTestE()
+		assert.Error(t, tst.ApplyMergePatch([]byte(patch)), patch)
+		assert.Equal(t, "one", tst.FieldA, patch)
+		assert.Empty(t, tst.AnalyzeChanges(), patch)
+	}
+
+	// Not initialized object
+	assert.Error(t, (&TestE{}).ApplyMergePatch([]byte(`{}`)))
+}
+
+func TestChangedFields_MergePatch(t *testing.T) {
+	var tst = &TestE{
+		FieldA: "one",
+		FieldB: &TestB{FieldA: "a"},
+		FieldD: []*TestD{{ID: 1}, {ID: 2}},
+		FieldF: map[string]string{"a": "1", "b": "2"},
+		FieldG: map[string]*TestC{"x": {FieldA: "x"}},
+	}
+	tst.ResetMutableState(tst)
+	tst.FieldA = "two"
+	tst.FieldB = nil
+	tst.FieldC.FieldA = "c"
+	tst.FieldD[1].FieldA = "changed"
+	tst.FieldF["a"] = "3"
+	delete(tst.FieldF, "b")
+	tst.FieldG["x"].FieldA = "y"
+
+	var document map[string]interface{}
+	assert.NoError(t, json.Unmarshal(tst.AnalyzeChanges().MergePatch(), &document))
+	var expected map[string]interface{}
+	json.Unmarshal([]byte(`{
+		"field_a": "two",
+		"field_b": null,
+		"field/c": {"field_a": "c"},
+		"field_d": [{"id": 1, "field_a": ""}, {"id": 2, "field_a": "changed"}],
+		"field_f": {"a": "3", "b": null},
+		"field_g": {"x": {"field_a": "y"}}
+	}`), &expected)
+	assert.Equal(t, expected, document)
+
+	// Generated patch applied to an original object gives a current one
+	original := &TestE{
+		FieldA: "one",
+		FieldB: &TestB{FieldA: "a"},
+		FieldD: []*TestD{{ID: 1}, {ID: 2}},
+		FieldF: map[string]string{"a": "1", "b": "2"},
+		FieldG: map[string]*TestC{"x": {FieldA: "x"}},
+	}
+	original.ResetMutableState(original)
+	if assert.NoError(t, original.ApplyMergePatch(tst.AnalyzeChanges().MergePatch())) {
+		assert.Equal(t, string(marshal(tst)), string(marshal(original)))
+	}
+
+	assert.Equal(t, "{}", string(ChangedFields{}.MergePatch()))
+
+	// Collections which have become nil are removed as a whole
+	tst.ResetMutableState(tst)
+	tst.FieldF = nil
+	tst.FieldG = nil
+	patch := tst.AnalyzeChanges().MergePatch()
+	assert.JSONEq(t, `{"field_f": null, "field_g": null}`, string(patch))
+	if assert.NoError(t, original.ApplyMergePatch(patch)) {
+		assert.Nil(t, original.FieldF)
+		assert.Nil(t, original.FieldG)
+		assert.Equal(t, string(marshal(tst)), string(marshal(original)))
+	}
+}
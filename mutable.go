@@ -3,7 +3,6 @@ package mutable
 import (
 	"encoding/json"
 	"reflect"
-	"strings"
 
 	"github.com/go-ext/logger"
 	"github.com/pquerna/ffjson/ffjson"
@@ -15,19 +14,24 @@ var LevelSeparator = "/"
 // Mutable provides object changes tracking features and the way to set values to the struct dynamically
 // by a destination field name (including nested structs)
 type Mutable struct {
-	originalState interface{}   // Original state of an object
-	target        interface{}   // Pointer to a target object
-	MutableStatus Status        `json:"-"` // Mutable status of an object
-	ChangedFields ChangedFields `json:"-"` // Changed fields data
+	originalState interface{}            // Original state of an object (deep copy)
+	target        interface{}            // Pointer to a target object
+	analyzed      bool                   // Changed fields data is actual (no changes made with SetValue since the last analyze)
+	history       *history               // History of SetValue changes (nil if history is disabled)
+	checkpoints   map[string]interface{} // Named snapshots of an object (deep copies)
+	observers     *observers             // Change observers (nil if there are no observers)
+	paths         pathOptions            // Options of path based methods
+	MutableStatus Status                 `json:"-"` // Mutable status of an object
+	ChangedFields ChangedFields          `json:"-"` // Changed fields data
 }
 
 const (
-	flagIgnore         = "ignore"
-	flagDeepAnalyze    = "deep"
-	mutTypeName        = "mutable.Mutable"
-	mutFieldName       = "Mutable"
-	mutStatusFieldName = "MutableStatus"
-	mutTagName         = "mutable"
+	flagIgnore      = "ignore"
+	flagIgnored     = "ignored"
+	flagDeepAnalyze = "deep"
+	flagKey         = "key="
+	mutFieldName    = "Mutable"
+	mutTagName      = "mutable"
 )
 
 // ResetMutableState resets current mutable state and updates original state with given self value
@@ -40,34 +44,56 @@ func (m *Mutable) ResetMutableState(self interface{}) error {
 	m.target = self
 	// Update mutable status
 	m.MutableStatus = NotChanged
-	// Reset original state
-	m.originalState = reflect.ValueOf(self).Elem().Interface()
+	// Reset original state with a deep copy of self to track in-place changes of slices, maps and pointers
+	m.originalState = deepCopy(reflect.ValueOf(self).Elem()).Interface()
 	// Reset changed fields arrays
 	m.ChangedFields = ChangedFields{}
+	m.analyzed = false
+	m.observers.reset()
 	// Reset all nested mutable objects
 	v := reflect.ValueOf(m.target).Elem()
 	for i := 0; i < v.NumField(); i++ {
-		f := v.Field(i)
-		if !isMutableType(f.Type()) {
-			continue
+		if err := resetNestedMutable(v.Field(i)); err != nil {
+			return err
 		}
-		if f.Kind() == reflect.Ptr {
-			f = v.Field(i).Elem()
+	}
+	return nil
+}
+
+// resetNestedMutable resets mutable state of a nested mutable object f or mutable elements of f collection
+func resetNestedMutable(f reflect.Value) error {
+	if !isMutableType(f.Type()) {
+		return nil
+	}
+	f = derefValue(f)
+	switch f.Kind() {
+	case reflect.Slice:
+		return resetSliceElements(f)
+	case reflect.Map:
+		return resetMapElements(f)
+	case reflect.Struct:
+		if err := f.Addr().Interface().(Mutabler).ResetMutableState(f.Addr().Interface()); err != nil {
+			logger.Error(err)
+			return errNestedResetError
 		}
-		switch f.Kind() {
-		case reflect.Slice:
-			if err := resetSliceElements(f); err != nil {
-				return err
-			}
-		case reflect.Map:
-			if err := resetMapElements(f); err != nil {
-				return err
-			}
-		case reflect.Struct:
-			if err := f.Addr().Interface().(Mutabler).ResetMutableState(f.Addr().Interface()); err != nil {
-				logger.Error(err)
-				return errNestedResetError
-			}
+	}
+	return nil
+}
+
+// restoreState sets a deep copy of snapshot to target keeping its own mutable state.
+// Nested mutable objects are reset with restored values
+func restoreState(target, snapshot reflect.Value) error {
+	restored := deepCopy(snapshot)
+	if mf := mutableField(target); mf.IsValid() {
+		mutableField(restored).Set(mf)
+	}
+	target.Set(restored)
+	if target.Kind() != reflect.Struct {
+		return nil
+	}
+	for i := 0; i < target.NumField(); i++ {
+		if err := resetNestedMutable(target.Field(i)); err != nil {
+			return err
 		}
 	}
 	return nil
@@ -78,6 +104,9 @@ func resetSliceElements(v reflect.Value) error {
 	for i := 0; i < v.Len(); i++ {
 		e := v.Index(i)
 		if e.Kind() == reflect.Ptr {
+			if e.IsNil() {
+				continue
+			}
 			e = e.Elem()
 		}
 		if err := e.Addr().Interface().(Mutabler).ResetMutableState(e.Addr().Interface()); err != nil {
@@ -90,16 +119,26 @@ func resetSliceElements(v reflect.Value) error {
 
 // resetMapElements resets mutable state of v map elements
 func resetMapElements(v reflect.Value) error {
-	if elmType := v.Type().Elem(); elmType.Kind() != reflect.Ptr {
-		logger.Warningf("map elements type is not addressable: %s (field type: %s)", elmType, v.Type())
-		return nil
-	}
 	for _, key := range v.MapKeys() {
 		elm := v.MapIndex(key)
-		if err := elm.Interface().(Mutabler).ResetMutableState(elm.Interface()); err != nil {
+		if elm.Kind() == reflect.Ptr {
+			if elm.IsNil() {
+				continue
+			}
+			if err := elm.Interface().(Mutabler).ResetMutableState(elm.Interface()); err != nil {
+				logger.Error(err)
+				return errNestedResetError
+			}
+			continue
+		}
+		// Map elements are not addressable, so reset a copy and put it back
+		elmCopy := reflect.New(elm.Type())
+		elmCopy.Elem().Set(elm)
+		if err := elmCopy.Interface().(Mutabler).ResetMutableState(elmCopy.Interface()); err != nil {
 			logger.Error(err)
 			return errNestedResetError
 		}
+		v.SetMapIndex(key, elmCopy.Elem())
 	}
 	return nil
 }
@@ -108,53 +147,135 @@ func resetMapElements(v reflect.Value) error {
 // JSON tag value will be used to find an appropriate field as a default source for a name, otherwise
 // a real (as it stated in struct) field name will be used.
 // Package var LevelSeparator value used as a separator for nested structs (eg. car/engine/price)
+// unless another path syntax is set with SetPathSyntax.
+// Slice and array elements are addressed by their indexes and map elements by their keys (eg. cars/2/engine/price,
+// prices/audi), non-string map keys are parsed into a key type. Setting a value to a not existing map key adds an element.
+// Nil pointers and nil maps along a path are allocated, missing map elements are added and a slice index equal to
+// a slice length appends an element (see SetStrictPaths)
 func (m *Mutable) SetValue(fieldName string, value interface{}) error {
-	// Try to set a value
-	return trySetValueToObject(reflect.ValueOf(m.target).Elem(), "", fieldName, value)
+	return m.setValue(fieldName, value, nil)
+}
+
+// SetStrictPaths makes SetValue fail on nil pointers, nil maps and missing elements along a path
+// instead of allocating them
+func (m *Mutable) SetStrictPaths(strict bool) {
+	m.paths.strict = strict
 }
 
-// AnalyzeChanges analyzes changes of a target object and returns changed fields data
+// SetPathSyntax sets a syntax of paths used by SetValue, GetValue, RevertField, AcceptField and change observers
+// of an object (eg. JSONPointerPath, DotPath or SeparatorPath). LevelSeparator separated paths are used if syntax is nil.
+// Patterns of change observers are parsed when they are subscribed
+func (m *Mutable) SetPathSyntax(syntax PathSyntax) {
+	m.paths.syntax = syntax
+}
+
+// setValue sets a value for given field by its name.
+// A field is set with a setter of generated code if it's given and handles the field, otherwise with reflection
+func (m *Mutable) setValue(fieldName string, value interface{}, setter FieldSetter) error {
+	var handled bool
+	var err error
+	var tokens []string
+	var oldValue reflect.Value
+	if m.history != nil || m.observers != nil {
+		// Keep an old value to be able to undo a change and to notify observers
+		tokens, oldValue = m.fieldValue(fieldName)
+	}
+	if setter != nil {
+		handled, err = setter(fieldName, value)
+	}
+	if !handled {
+		// Try to set a value
+		err = trySetValueToObject(reflect.ValueOf(m.target).Elem(), fieldName, value, m.paths)
+	}
+	if err != nil {
+		return err
+	}
+	// Invalidate analyzed changes of a target object and nested objects along a path.
+	// A setter of generated code invalidates nested objects itself
+	m.analyzed = false
+	if !handled {
+		if setTokens, ok := m.paths.splitExisting(reflect.ValueOf(m.target).Elem(), fieldName); ok {
+			invalidatePath(reflect.ValueOf(m.target).Elem(), setTokens)
+		}
+	}
+	if oldValue.IsValid() {
+		if newValue, err := pathValue(reflect.ValueOf(m.target).Elem(), tokens); err == nil {
+			if m.history != nil {
+				m.history.record(HistoryRecord{
+					FieldName: fieldName,
+					OldValue:  oldValue.Interface(),
+					NewValue:  newValue.Interface(),
+					tokens:    tokens,
+					oldValue:  oldValue,
+					newValue:  newValue,
+				})
+			}
+			if m.observers != nil {
+				// A path of the nearest existing level is notified if a field has been allocated
+				changedField := ChangedField{OldValue: oldValue.Interface(), NewValue: newValue.Interface()}
+				m.observers.notify(m.paths.pathSyntax(), tokens, changedField)
+				m.observers.record(tokens, changedField)
+			}
+		}
+	}
+	return nil
+}
+
+// AnalyzeChanges analyzes changes of a target object and returns changed fields data.
+// Analyzed changes are cached until the next SetValue or ResetMutableState call, so use AnalyzeChangesForce
+// if a target object has been changed directly
 func (m *Mutable) AnalyzeChanges() ChangedFields {
+	if m.analyzed {
+		// Return existing changes
+		return m.ChangedFields
+	}
+	return m.AnalyzeChangesForce()
+}
+
+// AnalyzeChangesForce analyzes changes of a target object regardless of cached changes and returns changed fields data
+func (m *Mutable) AnalyzeChangesForce() ChangedFields {
+	return m.analyzeChanges(nil)
+}
+
+// analyzeChanges analyzes changes of a target object with an analyzer of generated code if it's given,
+// otherwise with reflection
+func (m *Mutable) analyzeChanges(analyzer Analyzer) (changedFields ChangedFields) {
 	defer func() {
 		if r := recover(); r != nil {
 			logger.Error(r, m.target, m.originalState)
 		}
 	}()
-	// TODO: Return existing changes if it's not nil (add force param to be able to re-analyze)
-	return tryAnalyzeChanges(reflect.ValueOf(m.target).Elem(), reflect.ValueOf(m.originalState))
+	if analyzer != nil {
+		changedFields = analyzer(m.originalState)
+		m.updateState(changedFields)
+	} else {
+		changedFields = tryAnalyzeChanges(reflect.ValueOf(m.target).Elem(), reflect.ValueOf(m.originalState))
+	}
+	m.analyzed = true
+	if m.observers != nil {
+		m.observers.notifyAnalyzed(m.paths.pathSyntax(), changedFields)
+	}
+	return changedFields
 }
 
-// trySetValueToObject tries to set a value to a destination field of given object
-func trySetValueToObject(object reflect.Value, levelPrefix, dstFieldName string, value interface{}) error {
-	// Iterate over struct fields
-	for z := 0; z < object.NumField(); z++ {
-		field := object.Field(z)
-		if field.Kind() == reflect.Ptr {
-			// Get value the pointer points to
-			field = object.Field(z).Elem()
-		}
-		// Get current field's json name
-		fieldName, tagExists := object.Type().Field(z).Tag.Lookup("json")
-		if !tagExists {
-			// Get a field name from a struct metadata
-			fieldName = object.Type().Field(z).Name
-		}
-		if len(levelPrefix) > 0 {
-			// Prepend a level prefix
-			fieldName = levelPrefix + LevelSeparator + fieldName
-		}
-		if fieldName == dstFieldName {
-			if err := trySetValueToField(field, value); err != nil {
-				logger.Warningf("Error: %s, Field: %s", err, fieldName)
-				return errCannotSetValue(fieldName, value)
-			}
-			return nil
-		} else if field.Kind() == reflect.Struct && strings.HasPrefix(dstFieldName, fieldName+LevelSeparator) {
-			// Go down recursively
-			return trySetValueToObject(field, fieldName, dstFieldName, value)
-		}
+// trySetValueToObject tries to set a value to a destination field of given object.
+// Fields are looked up by a path of JSON names, slice and array indexes and map keys (see PathSyntax).
+// Nil pointers, nil maps and missing elements along a path are allocated unless paths are strict
+func trySetValueToObject(object reflect.Value, dstFieldName string, value interface{}, paths pathOptions) error {
+	tokens, ok := paths.split(object, dstFieldName)
+	if !ok {
+		return errCannotFind(dstFieldName)
 	}
-	return errCannotFind(dstFieldName)
+	err := walkPath(object, tokens, setFieldValue(value, !paths.strict))
+	if err != nil && !paths.strict {
+		// Some levels of a path may be missing
+		err = allocSetValue(object, tokens, value)
+	}
+	if err != nil {
+		logger.Warningf("Error: %s, Field: %s", err, dstFieldName)
+		return errCannotSetValue(dstFieldName, value)
+	}
+	return nil
 }
 
 // trySetValueToField sets the value to the given field
@@ -219,18 +340,7 @@ func parseValue(value []byte, dstType reflect.Type) (interface{}, error) {
 
 // isMutable reports whether a value is a mutable object
 func isMutable(value reflect.Value) bool {
-	if value.Kind() != reflect.Struct {
-		return false
-	}
-	// Try to get a Mutable field by name
-	mutableField := value.FieldByName(mutFieldName)
-	if mutableField.IsValid() {
-		switch mutableField.Interface().(type) {
-		case Mutable:
-			return true
-		}
-	}
-	return false
+	return mutableField(value).IsValid()
 }
 
 // isMutableType reports whether a value is a mutable object
@@ -253,30 +363,53 @@ F:
 	return ok
 }
 
+// isMutableElemType reports whether elements of t (array, slice or map) are mutable objects or pointers to them
+func isMutableElemType(t reflect.Type) bool {
+	elemType := t.Elem()
+	if elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+	return elemType.Kind() == reflect.Struct && isMutableType(elemType)
+}
+
 // setMutableStatus sets a status for a given value
 func setMutableStatus(value reflect.Value, status Status) {
-	mf := value.FieldByName(mutFieldName)
-	if mf.IsValid() {
-		mf.FieldByName(mutStatusFieldName).Set(reflect.ValueOf(status))
+	if mf := mutableField(value); mf.CanSet() {
+		mf.Addr().Interface().(*Mutable).MutableStatus = status
 	}
 }
 
-// appendChangedFields appends changed fields to a given object
-func appendChangedFields(object reflect.Value, changedFields ChangedFields) {
-	// Iterate over struct fields
-	for z := 0; z < object.NumField(); z++ {
-		switch object.Field(z).Interface().(type) {
-		case Mutable:
-			dst := object.Field(z).Interface().(Mutable).ChangedFields
-			// Check dst for nil
-			if dst == nil {
-				dst = ChangedFields{}
-			}
-			for _, field := range changedFields {
-				dst[field.Name] = field
-			}
-			return
-		}
+// updateMutableStatus sets Changed or NotChanged status for a given value according to changed flag
+func updateMutableStatus(value reflect.Value, changed bool) {
+	if mf := mutableField(value); mf.CanSet() {
+		mf.Addr().Interface().(*Mutable).updateStatus(changed)
+	}
+}
+
+// updateMutableState sets changed fields data and an appropriate status for a given mutable object
+func updateMutableState(object reflect.Value, changedFields ChangedFields) {
+	if mf := mutableField(object); mf.CanSet() {
+		mf.Addr().Interface().(*Mutable).updateState(changedFields)
+	}
+}
+
+// updateState sets changed fields data and an appropriate status
+// Cached changes are invalidated, as they are outdated if a parent object has analyzed m
+func (m *Mutable) updateState(changedFields ChangedFields) {
+	m.ChangedFields = changedFields
+	m.analyzed = false
+	m.updateStatus(len(changedFields) > 0)
+}
+
+// updateStatus sets Changed or NotChanged status according to changed flag.
+// Added status is kept as is because an added object remains added until its state is reset
+func (m *Mutable) updateStatus(changed bool) {
+	switch {
+	case m.MutableStatus == Added:
+	case changed:
+		m.MutableStatus = Changed
+	default:
+		m.MutableStatus = NotChanged
 	}
 }
 
@@ -284,59 +417,66 @@ func appendChangedFields(object reflect.Value, changedFields ChangedFields) {
 func tryAnalyzeChanges(currentValue, originalValue reflect.Value) (changedFields ChangedFields) {
 	changedFields = ChangedFields{}
 	// Iterate over struct fields
-	for z := 0; z < currentValue.NumField(); z++ {
-		// Get current and original fields
-		currentField := currentValue.Field(z)
-		originalField := originalValue.Field(z)
-		if currentField.Kind() == reflect.Ptr {
-			// Get value the pointer points to
-			currentField = currentValue.Field(z).Elem()
-			originalField = originalValue.Field(z).Elem()
-		}
-		// Get current field metadata
-		currentFieldMeta := currentValue.Type().Field(z)
-		tagValue, _ := currentFieldMeta.Tag.Lookup(mutTagName)
-		// Check the field for ignored flag
-		ignored := strings.Contains(tagValue, flagIgnore)
+	fields := getTypeInfo(currentValue.Type()).fields
+	for z := range fields {
 		// Check ignored fields and Mutable field itself
-		if currentFieldMeta.Type.String() == mutTypeName || ignored {
+		if fields[z].isMutable || fields[z].options.ignored {
 			// Pass through Mutable itself and ignored fields
 			continue
 		}
-		// Check whether a field has deep analyze flag
-		isDeepAnalyze := strings.Contains(tagValue, flagDeepAnalyze) && currentField.Kind() == reflect.Struct
-
-		// Analyze the field changes
-		switch {
-		case !currentField.IsValid() || !originalField.IsValid():
-			// Current or original field is not valid
-			if changedField := analyzeNotValid(currentFieldMeta.Name, currentField, originalField); changedField != nil {
-				changedFields[changedField.Name] = changedField
-			}
-		case isDeepAnalyze:
-			// Deep analyze case
-			if nestedChangedFields := analyzeDeep(currentField, originalField); len(nestedChangedFields) > 0 {
-				changedFields[currentFieldMeta.Name] = &ChangedField{
-					Name:         currentFieldMeta.Name,
-					NestedFields: nestedChangedFields,
-				}
-			}
-		default:
-			// Regular analyze case (simple value field)
-			if changedField := analyzeRegular(currentFieldMeta.Name, currentField, originalField); changedField != nil {
-				changedFields[changedField.Name] = changedField
-			}
+		changedField := analyzeField(&fields[z], currentValue.Field(fields[z].index), originalValue.Field(fields[z].index))
+		if changedField != nil {
+			changedField.JSONName = fields[z].jsonName
+			changedFields[changedField.Name] = changedField
 		}
 	}
-	// Set changed fields data to the current object
-	if len(changedFields) > 0 {
-		if isMutable(currentValue) {
-			appendChangedFields(currentValue, changedFields)
-		}
+	// Set changed fields data and a status to the current object
+	if isMutable(currentValue) {
+		updateMutableState(currentValue, changedFields)
 	}
 	return changedFields
 }
 
+// analyzeField analyzes changes of a struct field and returns changed field data or nil if it has not been changed
+func analyzeField(meta *fieldInfo, currentField, originalField reflect.Value) *ChangedField {
+	if !currentField.CanInterface() {
+		// Pass through unexported fields
+		return nil
+	}
+	// Get current and original fields (pointers are dereferenced)
+	currentField, originalField = derefValue(currentField), derefValue(originalField)
+	// Check whether a field has deep analyze flag
+	isDeepAnalyze := meta.options.deep && currentField.Kind() == reflect.Struct
+
+	// Analyze the field changes
+	switch {
+	case !currentField.IsValid() || !originalField.IsValid():
+		// Current or original field is not valid
+		return analyzeNotValid(meta.name, currentField, originalField)
+	case currentField.Kind() == reflect.Slice && isMutableElemType(currentField.Type()):
+		// Element-level analyze of a slice of mutable objects
+		return analyzeSlice(meta.name, currentField, originalField, meta.options.key)
+	case currentField.Kind() == reflect.Map:
+		// Per key analyze of a map
+		return analyzeMap(meta.name, currentField, originalField)
+	case isDeepAnalyze:
+		// Deep analyze case
+		if nestedChangedFields := analyzeDeep(currentField, originalField); len(nestedChangedFields) > 0 {
+			return &ChangedField{
+				Name:         meta.name,
+				NestedFields: nestedChangedFields,
+			}
+		}
+		return nil
+	default:
+		// Regular analyze case (simple value field)
+		changedField := analyzeRegular(meta.name, currentField, originalField)
+		// Update a status of a nested mutable object
+		updateMutableStatus(currentField, changedField != nil)
+		return changedField
+	}
+}
+
 // analyzeNotValid analyzes a case when current or original value is not valid
 func analyzeNotValid(fieldName string, current, original reflect.Value) *ChangedField {
 	switch {
@@ -346,7 +486,7 @@ func analyzeNotValid(fieldName string, current, original reflect.Value) *Changed
 		// Current is not valid
 		return &ChangedField{
 			Name:     fieldName,
-			OldValue: original.Interface(),
+			OldValue: removedInterface(original),
 			NewValue: nil,
 		}
 	case !original.IsValid() && current.IsValid():
@@ -354,7 +494,7 @@ func analyzeNotValid(fieldName string, current, original reflect.Value) *Changed
 		return &ChangedField{
 			Name:     fieldName,
 			OldValue: nil,
-			NewValue: current.Interface(),
+			NewValue: addedInterface(current),
 		}
 	}
 	return nil
@@ -362,21 +502,13 @@ func analyzeNotValid(fieldName string, current, original reflect.Value) *Changed
 
 // analyzeDeep returns changed fields of deep analyze logic.
 // Deep analyze logic is the analyze of every field changes of underlying struct (used only for struct values)
+// Nested mutable objects are analyzed against the original state of the parent object as well
+// to never get outdated cached changes of nested objects
 func analyzeDeep(current, original reflect.Value) (changedFields ChangedFields) {
 	if !current.CanInterface() {
 		return changedFields
 	}
-	// Check whether a nested struct is mutable
-	isNestedStructMutable := isMutable(current)
-	// Analyze nested struct
-	if isNestedStructMutable {
-		// Analyze with nested object's own mutable logic
-		changedFields = current.Addr().Interface().(Mutabler).AnalyzeChanges()
-	} else {
-		// Analyze as non-mutable struct
-		changedFields = tryAnalyzeChanges(current, original)
-	}
-	return changedFields
+	return tryAnalyzeChanges(current, original)
 }
 
 // analyzeRegular returns changed fields of regular analyze.
@@ -385,15 +517,7 @@ func analyzeRegular(fieldName string, current, original reflect.Value) *ChangedF
 	if !current.CanInterface() {
 		return nil
 	}
-	var equals bool
-	if _, ok := current.Interface().(Equaler); ok {
-		// Compare with type's Equal method
-		equals = current.Interface().(Equaler).Equal(original.Interface())
-	} else {
-		// Compare with reflect's DeepEqual
-		equals = reflect.DeepEqual(current.Interface(), original.Interface())
-	}
-	if !equals {
+	if !isEqual(current, original) {
 		return &ChangedField{
 			Name:     fieldName,
 			OldValue: original.Interface(),
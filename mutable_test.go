@@ -22,6 +22,18 @@ type TestA struct {
 	FieldK []int             `json:"field_k"`
 }
 
+type TestG struct {
+	Mutable
+	FieldA []*TestC          `json:"field_a"`
+	FieldB map[string]*TestC `json:"field_b"`
+	FieldC []TestC           `json:"field_c"`
+	FieldD map[string]TestC  `json:"field_d"`
+	FieldE []int             `json:"field_e"`
+	FieldF [2]TestB          `json:"field_f"`
+	FieldG map[int]string    `json:"field_g"`
+	FieldH map[string]string `json:"field_h"`
+}
+
 type TestB struct {
 	FieldA string `json:"field_a"`
 	FieldB []int  `json:"field_b"`
@@ -54,7 +66,9 @@ func TestMutable_ResetMutableState(t *testing.T) {
 			TestC{},
 			TestC{},
 		},
-		FieldJ: map[string]TestC{},
+		FieldJ: map[string]TestC{
+			"one": TestC{},
+		},
 		FieldK: []int{0, 1},
 	}
 	// Check an arg type checking
@@ -101,6 +115,12 @@ func TestMutable_ResetMutableState(t *testing.T) {
 		assert.True(t, ok, "nested mutable original state object type")
 	}
 
+	// Check an original state of a nested map of non-pointer mutable objects
+	for key := range tst.FieldJ {
+		_, ok = tst.FieldJ[key].originalState.(TestC)
+		assert.True(t, ok, "nested mutable original state object type")
+	}
+
 	// Check a target of a nested slice of mutable objects
 	for i := range tst.FieldI {
 		target, ok := tst.FieldI[i].target.(*TestC)
@@ -158,6 +178,18 @@ func TestMutable_SetValue(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "noJSON", obj.FieldE)
 
+	// Try to set a value for a field with a name containing a level separator
+	var objSep = &struct {
+		Mutable
+		FieldA string `json:"field/a,omitempty"`
+		FieldB TestB  `json:"field"`
+	}{}
+	assert.NoError(t, objSep.ResetMutableState(objSep), "init")
+	assert.NoError(t, objSep.SetValue("field/a", "one"))
+	assert.Equal(t, "one", objSep.FieldA)
+	assert.NoError(t, objSep.SetValue("field/field_a", "two"))
+	assert.Equal(t, "two", objSep.FieldB.FieldA)
+
 	// Try to set a value for not existing field
 	err = obj.SetValue("wrong_field", "two")
 	if assert.Error(t, err) {
@@ -165,6 +197,109 @@ func TestMutable_SetValue(t *testing.T) {
 	}
 }
 
+func TestMutable_SetValue_indexed(t *testing.T) {
+	var tst = &TestG{
+		FieldA: []*TestC{{}, {}, {}},
+		FieldB: map[string]*TestC{"one": {}},
+		FieldC: []TestC{{}},
+		FieldD: map[string]TestC{"one": {FieldA: "a"}},
+		FieldE: []int{1, 2},
+		FieldG: map[int]string{1: "a"},
+		FieldH: map[string]string{"a/b": "c"},
+	}
+	assert.NoError(t, tst.ResetMutableState(tst))
+
+	assert.NoError(t, tst.SetValue("field_a/2/field_a", "x"))
+	assert.Equal(t, "x", tst.FieldA[2].FieldA)
+	assert.NoError(t, tst.SetValue("field_b/one/field_b", "[1]"))
+	assert.Equal(t, []int{1}, tst.FieldB["one"].FieldB)
+	assert.NoError(t, tst.SetValue("field_c/0/field_a", "y"))
+	assert.Equal(t, "y", tst.FieldC[0].FieldA)
+	// Not addressable map elements are put back
+	assert.NoError(t, tst.SetValue("field_d/one/field_b", "[2]"))
+	assert.Equal(t, "a", tst.FieldD["one"].FieldA)
+	assert.Equal(t, []int{2}, tst.FieldD["one"].FieldB)
+	assert.NoError(t, tst.SetValue("field_e/1", 3))
+	assert.Equal(t, []int{1, 3}, tst.FieldE)
+	assert.NoError(t, tst.SetValue("field_f/1/field_a", "z"))
+	assert.Equal(t, "z", tst.FieldF[1].FieldA)
+	// Typed and new keys
+	assert.NoError(t, tst.SetValue("field_g/1", "b"))
+	assert.NoError(t, tst.SetValue("field_g/2", "c"))
+	assert.Equal(t, map[int]string{1: "b", 2: "c"}, tst.FieldG)
+	assert.NoError(t, tst.SetValue("field_b/two", &TestC{FieldA: "ptr"}))
+	if assert.NotNil(t, tst.FieldB["two"]) {
+		assert.Equal(t, "ptr", tst.FieldB["two"].FieldA)
+	}
+	// Keys containing a level separator
+	assert.NoError(t, tst.SetValue("field_h/a/b", "d"))
+	assert.Equal(t, "d", tst.FieldH["a/b"])
+
+	changes := tst.AnalyzeChanges()
+	assert.Len(t, changes, 8)
+	assert.Equal(t, "x", changes["FieldA"].NestedFields["2"].NestedFields["FieldA"].NewValue)
+
+	// Errors
+	for _, path := range []string{"field_a/4/field_a", "field_a/-1/field_a", "field_a/x", "field_g/x", "field_e/3", "field_e/0/x"} {
+		err := tst.SetValue(path, "1")
+		if assert.Error(t, err, path) {
+			assert.True(t, IsCannotFindErr(err), path)
+		}
+	}
+	assert.True(t, IsCannotSetErr(tst.SetValue("field_e/0", "x")))
+}
+
+func TestMutable_SetValue_allocate(t *testing.T) {
+	var tst = &TestG{}
+	assert.NoError(t, tst.ResetMutableState(tst))
+	tst.EnableHistory(0)
+
+	assert.NoError(t, tst.SetValue("field_b/one/field_a", "x"))
+	if assert.NotNil(t, tst.FieldB["one"]) {
+		assert.Equal(t, "x", tst.FieldB["one"].FieldA)
+	}
+	assert.NoError(t, tst.SetValue("field_d/one/field_b", "[1]"))
+	assert.Equal(t, []int{1}, tst.FieldD["one"].FieldB)
+	assert.NoError(t, tst.SetValue("field_a/0/field_a", "y"))
+	assert.NoError(t, tst.SetValue("field_a/1/field_a", "z"))
+	if assert.Len(t, tst.FieldA, 2) {
+		assert.Equal(t, "y", tst.FieldA[0].FieldA)
+		assert.Equal(t, "z", tst.FieldA[1].FieldA)
+	}
+	assert.NoError(t, tst.SetValue("field_e/0", 5))
+	assert.Equal(t, []int{5}, tst.FieldE)
+	// Only an index equal to a slice length appends an element
+	assert.True(t, IsCannotFindErr(tst.SetValue("field_e/2", 5)))
+	// A path isn't allocated for an unsuitable value
+	assert.True(t, IsCannotSetErr(tst.SetValue("field_c/0/field_b", "x")))
+	assert.Nil(t, tst.FieldC)
+
+	var a = &TestA{}
+	assert.NoError(t, a.ResetMutableState(a))
+	assert.NoError(t, a.SetValue("field_e/field_a", "x"))
+	if assert.NotNil(t, a.FieldE) {
+		assert.Equal(t, "x", a.FieldE.FieldA)
+	}
+	assert.True(t, a.AnalyzeChanges().Contains("FieldE"))
+
+	// Allocations are undone
+	assert.NoError(t, tst.Undo())
+	assert.Nil(t, tst.FieldE)
+	for tst.CanUndo() {
+		assert.NoError(t, tst.Undo())
+	}
+	assert.Nil(t, tst.FieldB)
+	assert.Nil(t, tst.FieldA)
+
+	// Strict paths
+	tst.SetStrictPaths(true)
+	for _, path := range []string{"field_b/one/field_a", "field_a/0/field_a", "field_e/0"} {
+		assert.Error(t, tst.SetValue(path, "1"), path)
+	}
+	assert.True(t, IsCannotSetErr(tst.SetValue("field_b/one", "{}")))
+	assert.Nil(t, tst.FieldB)
+}
+
 func TestMutable_setMutableStatus(t *testing.T) {
 	// Create an object of TestA type
 	var obj = &TestA{}
@@ -173,6 +308,66 @@ func TestMutable_setMutableStatus(t *testing.T) {
 	assert.Equal(t, Added, obj.MutableStatus, "status")
 }
 
+func TestMutable_AnalyzeChanges_status(t *testing.T) {
+	tst := &TestA{
+		FieldE: &TestB{},
+		FieldF: TestC{FieldA: "apple"},
+		FieldG: []*TestC{&TestC{FieldA: "one"}, &TestC{FieldA: "two"}, &TestC{FieldA: "three"}},
+		FieldH: map[string]*TestC{"one": &TestC{}},
+		FieldJ: map[string]TestC{"one": TestC{}, "two": TestC{}},
+	}
+	assert.NoError(t, tst.ResetMutableState(tst))
+	assert.Empty(t, tst.AnalyzeChanges())
+	assert.Equal(t, NotChanged, tst.MutableStatus, "root")
+
+	tst.FieldF.FieldA = "banana"
+	tst.FieldG[2].FieldA = "four"
+	tst.FieldG = tst.FieldG[1:]
+	tst.FieldH["two"] = &TestC{}
+	tst.FieldJ["one"] = TestC{FieldA: "changed"}
+	changes := tst.AnalyzeChangesForce()
+	assert.Equal(t, Changed, tst.MutableStatus, "root")
+	assert.Equal(t, Changed, tst.FieldF.MutableStatus, "deep field")
+	assert.Equal(t, NotChanged, tst.FieldG[0].MutableStatus, "not changed slice element")
+	assert.Equal(t, Changed, tst.FieldG[1].MutableStatus, "changed slice element")
+	assert.Equal(t, Removed, changes["FieldG"].NestedFields["0"].OldValue.(TestC).MutableStatus, "removed slice element")
+	assert.Equal(t, NotChanged, tst.FieldH["one"].MutableStatus, "not changed map element")
+	assert.Equal(t, Added, tst.FieldH["two"].MutableStatus, "added map element")
+	assert.Equal(t, Changed, tst.FieldJ["one"].MutableStatus, "changed map element")
+	assert.Equal(t, NotChanged, tst.FieldJ["two"].MutableStatus, "not changed map element")
+	assert.Equal(t, changes, tst.ChangedFields, "root changed fields")
+
+	// Revert changes manually
+	tst.FieldF.FieldA = "apple"
+	tst.AnalyzeChangesForce()
+	assert.Equal(t, NotChanged, tst.FieldF.MutableStatus, "deep field")
+	assert.Equal(t, Added, tst.FieldH["two"].MutableStatus, "added map element")
+}
+
+func TestMutable_AnalyzeChanges_cache(t *testing.T) {
+	tst := &TestA{FieldA: "apple"}
+	assert.NoError(t, tst.ResetMutableState(tst))
+	tst.FieldA = "banana"
+	// The first analyze after a reset
+	changes := tst.AnalyzeChanges()
+	assert.Len(t, changes, 1)
+
+	// Direct changes are not visible for cached analyze
+	tst.FieldB = 1
+	assert.Equal(t, changes, tst.AnalyzeChanges(), "cached")
+	assert.Len(t, tst.AnalyzeChangesForce(), 2, "forced")
+
+	// Changes made with SetValue invalidate cached changes
+	assert.NoError(t, tst.SetValue("field_c", "1"))
+	assert.Len(t, tst.AnalyzeChanges(), 3, "after SetValue")
+	assert.Len(t, tst.ChangedFields, 3, "changed fields")
+
+	// Changes of nested objects made with SetValue of a parent
+	assert.NoError(t, tst.SetValue("field_f/field_a", "tree"))
+	assert.True(t, tst.AnalyzeChanges().Contains("FieldF"), "nested changes")
+	assert.Equal(t, Changed, tst.FieldF.MutableStatus, "nested status")
+}
+
 func TestMutable_isMutable(t *testing.T) {
 	// Create an object of TestA type
 	var mut = &TestA{}
@@ -246,13 +441,18 @@ func TestMutable_AnalyzeChanges(t *testing.T) {
 					FieldC: &TestB{FieldA: "tree"},
 				}
 				tst.ResetMutableState(tst)
-				// FieldA changes shouldn't be tracked because we use it as pointer
+				// FieldA changes should be tracked as well because original state is a deep copy
 				tst.FieldA.FieldA = "banana"
 				tst.FieldB = &TestB{FieldA: "car"}
 				tst.FieldC = nil
 				return tst.AnalyzeChanges()
 			},
 			expected: ChangedFields{
+				"FieldA": &ChangedField{
+					Name:     "FieldA",
+					OldValue: TestB{FieldA: "apple"},
+					NewValue: TestB{FieldA: "banana"},
+				},
 				"FieldB": &ChangedField{
 					Name:     "FieldB",
 					OldValue: nil,
@@ -282,7 +482,7 @@ func TestMutable_AnalyzeChanges(t *testing.T) {
 				tst.ResetMutableState(tst)
 				tst.FieldA.FieldA = "banana"
 				tst.FieldB.FieldA = "stone"
-				// FieldC changes shouldn't be tracked because we use it as pointer and have no deep tag
+				// FieldC changes should be tracked as a regular field because it has no deep tag
 				tst.FieldC.FieldA = "green"
 				tst.FieldD.FieldA = "cat"
 				return tst.AnalyzeChanges()
@@ -303,6 +503,11 @@ func TestMutable_AnalyzeChanges(t *testing.T) {
 						},
 					},
 				},
+				"FieldC": &ChangedField{
+					Name:     "FieldC",
+					OldValue: TestC{FieldA: "red"},
+					NewValue: TestC{FieldA: "green"},
+				},
 				"FieldD": &ChangedField{
 					Name: "FieldD",
 					NestedFields: ChangedFields{
@@ -314,6 +519,166 @@ func TestMutable_AnalyzeChanges(t *testing.T) {
 					},
 				},
 			},
+		}, {
+			name: "inPlaceSliceAndMap",
+			scenario: func() ChangedFields {
+				tst := &struct {
+					Mutable
+					FieldA []int
+					FieldB map[string]string
+					FieldC []int
+				}{
+					FieldA: []int{1, 2},
+					FieldB: map[string]string{"x": "a"},
+					FieldC: []int{3},
+				}
+				tst.ResetMutableState(tst)
+				tst.FieldA[0] = 5
+				tst.FieldB["x"] = "y"
+				return tst.AnalyzeChanges()
+			},
+			expected: ChangedFields{
+				"FieldA": &ChangedField{
+					Name:     "FieldA",
+					OldValue: []int{1, 2},
+					NewValue: []int{5, 2},
+				},
+				"FieldB": &ChangedField{
+					Name: "FieldB",
+					NestedFields: ChangedFields{
+						"x": &ChangedField{
+							Name:     "x",
+							OldValue: "a",
+							NewValue: "y",
+							Status:   Changed,
+						},
+					},
+				},
+			},
+		}, {
+			name: "sliceOfMutStructs",
+			scenario: func() ChangedFields {
+				tst := &TestA{
+					FieldG: []*TestC{&TestC{FieldA: "apple"}, &TestC{FieldA: "tree"}, &TestC{FieldA: "red"}},
+					FieldI: []TestC{TestC{FieldA: "dog"}},
+				}
+				tst.ResetMutableState(tst)
+				tst.FieldG[1].FieldA = "stone"
+				tst.FieldG = tst.FieldG[:2]
+				tst.FieldI = append(tst.FieldI, TestC{FieldA: "cat"})
+				return tst.AnalyzeChanges()
+			},
+			expected: ChangedFields{
+				"FieldG": &ChangedField{
+					Name: "FieldG",
+					NestedFields: ChangedFields{
+						"1": &ChangedField{
+							Name: "1",
+							NestedFields: ChangedFields{
+								"FieldA": &ChangedField{
+									Name:     "FieldA",
+									OldValue: "tree",
+									NewValue: "stone",
+								},
+							},
+							Status: Changed,
+						},
+						"2": &ChangedField{
+							Name:     "2",
+							OldValue: TestC{FieldA: "red"},
+							Status:   Removed,
+						},
+					},
+				},
+				"FieldI": &ChangedField{
+					Name: "FieldI",
+					NestedFields: ChangedFields{
+						"1": &ChangedField{
+							Name:     "1",
+							NewValue: TestC{FieldA: "cat"},
+							Status:   Added,
+						},
+					},
+				},
+			},
+		}, {
+			name: "mapOfMutStructs",
+			scenario: func() ChangedFields {
+				tst := &TestA{
+					FieldH: map[string]*TestC{"one": &TestC{FieldA: "apple"}, "two": &TestC{FieldA: "tree"}},
+					FieldJ: map[string]TestC{"one": TestC{FieldA: "dog"}},
+				}
+				tst.ResetMutableState(tst)
+				tst.FieldH["one"].FieldA = "banana"
+				delete(tst.FieldH, "two")
+				tst.FieldJ["one"] = TestC{FieldA: "cat"}
+				tst.FieldJ["two"] = TestC{FieldA: "fox"}
+				return tst.AnalyzeChanges()
+			},
+			expected: ChangedFields{
+				"FieldH": &ChangedField{
+					Name: "FieldH",
+					NestedFields: ChangedFields{
+						"one": &ChangedField{
+							Name: "one",
+							NestedFields: ChangedFields{
+								"FieldA": &ChangedField{
+									Name:     "FieldA",
+									OldValue: "apple",
+									NewValue: "banana",
+								},
+							},
+							Status: Changed,
+						},
+						"two": &ChangedField{
+							Name:     "two",
+							OldValue: TestC{FieldA: "tree"},
+							Status:   Removed,
+						},
+					},
+				},
+				"FieldJ": &ChangedField{
+					Name: "FieldJ",
+					NestedFields: ChangedFields{
+						"one": &ChangedField{
+							Name: "one",
+							NestedFields: ChangedFields{
+								"FieldA": &ChangedField{
+									Name:     "FieldA",
+									OldValue: "dog",
+									NewValue: "cat",
+								},
+							},
+							Status: Changed,
+						},
+						"two": &ChangedField{
+							Name:     "two",
+							NewValue: TestC{FieldA: "fox"},
+							Status:   Added,
+						},
+					},
+				},
+			},
+		}, {
+			name: "notChangedNestedMutStruct",
+			scenario: func() ChangedFields {
+				tst := &struct {
+					Mutable
+					FieldA TestC
+					FieldB *TestC
+				}{
+					FieldA: TestC{FieldA: "apple"},
+					FieldB: &TestC{FieldA: "tree"},
+				}
+				tst.ResetMutableState(tst)
+				// Nested mutable state changes shouldn't be tracked
+				tst.FieldA.MutableStatus = Changed
+				tst.FieldB.SetValue("field_b", "[1]")
+				tst.FieldB.SetValue("field_b", "null")
+				tst.FieldB.AnalyzeChanges()
+				return tst.AnalyzeChanges()
+			},
+			expected: ChangedFields{},
 		},
 	}
 
@@ -370,3 +735,33 @@ func TestMutable_unexportedFields(t *testing.T) {
 	}
 	assert.Equal(t, expectedChanges.String(), changes.String(), "Expected: %s\nActual: %s\ntestCase: %s", expectedChanges.String(), changes.String())
 }
+
+func TestMutable_invalidateNested(t *testing.T) {
+	var tst = &TestA{FieldG: []*TestC{{}}}
+	assert.NoError(t, tst.ResetMutableState(tst))
+	tst.EnableHistory(0)
+	for _, change := range []struct {
+		name   string
+		nested *TestC
+		apply  func() error
+		count  int
+	}{
+		{"SetValue", &tst.FieldF, func() error { return tst.SetValue("field_f/field_a", "b") }, 1},
+		{"Undo", &tst.FieldF, tst.Undo, 0},
+		{"SetValue element", tst.FieldG[0], func() error { return tst.SetValue("field_g/0/field_a", "b") }, 1},
+		{"RevertField", tst.FieldG[0], func() error { return tst.RevertField("field_g/0/field_a") }, 0},
+		{"ApplyJSONPatch", &tst.FieldF, func() error {
+			return tst.ApplyJSONPatch([]byte(`[{"op":"replace","path":"/field_f/field_a","value":"c"}]`))
+		}, 1},
+		{"ApplyMergePatch", &tst.FieldF, func() error {
+			return tst.ApplyMergePatch([]byte(`{"field_f":{"field_a":null}}`))
+		}, 0},
+	} {
+		// Cache changes of a nested object before a change
+		tst.AnalyzeChanges()
+		change.nested.AnalyzeChanges()
+		assert.NoError(t, change.apply(), change.name)
+		assert.Len(t, change.nested.AnalyzeChanges(), change.count, change.name)
+		assert.Equal(t, change.nested.AnalyzeChangesForce(), change.nested.AnalyzeChanges(), change.name)
+	}
+}
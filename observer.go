@@ -0,0 +1,225 @@
+package mutable
+
+import (
+	"path"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-ext/logger"
+)
+
+// observer is a callback subscribed to changes of fields with paths matching a pattern
+type observer struct {
+	id      int
+	pattern []string // Pattern levels
+	fn      func(changedField ChangedField, seq uint64, t time.Time)
+}
+
+// observers keeps change observers of an object and changes already reported to them
+type observers struct {
+	seq      uint64     // Sequence number of the latest change event (see Subscribe), first to be 64-bit aligned
+	mu       sync.Mutex // Guards list and lastID, observers may be unsubscribed from another goroutine
+	list     []observer
+	lastID   int
+	reported map[string]reportedChange // Changes reported after the latest analyze by path tokens joined with NUL
+}
+
+// reportedChange is a change of a field reported to observers
+type reportedChange struct {
+	tokens []string // Path tokens of a field
+	field  ChangedField
+	set    bool // Change has been reported by SetValue
+}
+
+// OnChange subscribes fn to changes of fields with paths matching a glob pattern and returns a function to unsubscribe.
+// Paths consist of JSON names of fields, collection element indexes and keys (see SetValue and SetPathSyntax).
+// Pattern levels are matched with path.Match, "**" level matches any number of levels (eg. field_c/*, items/**).
+// Fn is called with a field path as a name when a matching field is set with SetValue or when a change of
+// a matching field is newly detected by AnalyzeChanges
+func (m *Mutable) OnChange(pattern string, fn func(ChangedField)) (unsubscribe func()) {
+	return m.observe(pattern, func(changedField ChangedField, _ uint64, _ time.Time) {
+		fn(changedField)
+	})
+}
+
+// observe subscribes fn to changes of fields with paths matching a pattern and returns a function to unsubscribe.
+// Fn gets a sequence number and time of a change as well
+func (m *Mutable) observe(pattern string, fn func(changedField ChangedField, seq uint64, t time.Time)) (unsubscribe func()) {
+	levels := levelNames(m.paths.pathSyntax(), pattern)
+	for _, level := range levels {
+		if _, err := path.Match(level, ""); err != nil {
+			logger.Warningf("Error: %s, Pattern: %s", err, pattern)
+		}
+	}
+	if m.observers == nil {
+		m.observers = &observers{}
+	}
+	o := m.observers
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.lastID++
+	id := o.lastID
+	o.list = append(o.list[:len(o.list):len(o.list)], observer{id: id, pattern: levels, fn: fn})
+	return func() {
+		o.mu.Lock()
+		defer o.mu.Unlock()
+		for i := range o.list {
+			if o.list[i].id == id {
+				o.list = append(o.list[:i:i], o.list[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// notify calls observers subscribed to a changed field with given path tokens formatted with a syntax
+func (o *observers) notify(syntax PathSyntax, tokens []string, changedField ChangedField) {
+	changedField.Name = syntax.Format(tokens)
+	// Levels of a formatted path are matched, so pattern levels may be parts of names containing a separator
+	levels := levelNames(syntax, changedField.Name)
+	o.mu.Lock()
+	list := o.list
+	o.mu.Unlock()
+	seq, now := atomic.AddUint64(&o.seq, 1), time.Now()
+	for _, obs := range list {
+		if matchLevels(obs.pattern, levels) {
+			obs.fn(changedField, seq, now)
+		}
+	}
+}
+
+// notifyAnalyzed calls observers subscribed to changes which have not been reported after the previous analyze.
+// A field having nested changes is notified if it has not been reported or any of its nested changes is notified
+func (o *observers) notifyAnalyzed(syntax PathSyntax, changedFields ChangedFields) {
+	reported := o.reported
+	o.reported = map[string]reportedChange{}
+	o.collect(nil, changedFields, false)
+	var notified []string
+	for fieldPath, change := range o.reported {
+		if len(change.field.NestedFields) > 0 {
+			continue
+		}
+		if previous, ok := reported[fieldPath]; !ok || !previous.isSame(change.field) {
+			notified = append(notified, fieldPath)
+		}
+	}
+	for fieldPath, change := range o.reported {
+		if len(change.field.NestedFields) == 0 {
+			continue
+		}
+		if _, ok := reported[fieldPath]; !ok {
+			notified = append(notified, fieldPath)
+			continue
+		}
+		for _, nestedPath := range notified {
+			if isPathPrefix(change.tokens, o.reported[nestedPath].tokens) {
+				notified = append(notified, fieldPath)
+				break
+			}
+		}
+	}
+	sort.Strings(notified)
+	for _, fieldPath := range notified {
+		change := o.reported[fieldPath]
+		o.notify(syntax, change.tokens, change.field)
+	}
+}
+
+// record stores a change of a field with given path tokens reported by SetValue, so it's not reported
+// by AnalyzeChanges again. Parents of a field are stored as reported ones unless they have been reported already
+func (o *observers) record(tokens []string, changedField ChangedField) {
+	if o.reported == nil {
+		o.reported = map[string]reportedChange{}
+	}
+	o.reported[strings.Join(tokens, "\x00")] = reportedChange{tokens: tokens, field: changedField, set: true}
+	for i := len(tokens) - 1; i > 0; i-- {
+		if fieldPath := strings.Join(tokens[:i], "\x00"); len(o.reported[fieldPath].tokens) == 0 {
+			o.reported[fieldPath] = reportedChange{tokens: tokens[:i], set: true}
+		}
+	}
+}
+
+// collect adds changes of every field within changedFields and their nested fields to reported ones.
+// Slice elements are addressed by their indexes (as SetValue does) rather than by their names which may be key values
+func (o *observers) collect(prefix []string, changedFields ChangedFields, elements bool) {
+	for _, field := range changedFields {
+		name := field.JSONName
+		if len(name) == 0 {
+			name = field.Name
+		}
+		if elements {
+			name = strconv.Itoa(field.NewIndex)
+			if field.Status == Removed {
+				name = strconv.Itoa(field.OldIndex)
+			}
+		}
+		tokens := append(prefix[:len(prefix):len(prefix)], name)
+		fieldPath := strings.Join(tokens, "\x00")
+		o.reported[fieldPath] = reportedChange{tokens: tokens, field: *field}
+		o.collect(tokens, field.NestedFields, field.kind == reflect.Slice)
+	}
+}
+
+// reset forgets reported changes
+func (o *observers) reset() {
+	if o != nil {
+		o.reported = nil
+	}
+}
+
+// isSame reports whether changedField has been reported already: it's the same change or
+// a field has been set to the same value with SetValue
+func (r reportedChange) isSame(changedField ChangedField) bool {
+	if r.set {
+		return isEqual(reflect.ValueOf(r.field.NewValue), reflect.ValueOf(changedField.NewValue))
+	}
+	return isSameChange(r.field, changedField)
+}
+
+// isSameChange reports whether a and b are the same changes of a field
+func isSameChange(a, b ChangedField) bool {
+	return a.Status == b.Status && len(a.NestedFields) == len(b.NestedFields) &&
+		isEqual(reflect.ValueOf(a.OldValue), reflect.ValueOf(b.OldValue)) &&
+		isEqual(reflect.ValueOf(a.NewValue), reflect.ValueOf(b.NewValue))
+}
+
+// isPathPrefix reports whether prefix tokens are a beginning of longer path tokens
+func isPathPrefix(prefix, tokens []string) bool {
+	if len(prefix) >= len(tokens) {
+		return false
+	}
+	for i := range prefix {
+		if prefix[i] != tokens[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// matchLevels reports whether path levels match pattern levels
+func matchLevels(pattern, levels []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			// Try to match the rest of a pattern with every tail of levels
+			for i := 0; i <= len(levels); i++ {
+				if matchLevels(pattern[1:], levels[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(levels) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], levels[0]); !ok {
+			return false
+		}
+		pattern, levels = pattern[1:], levels[1:]
+	}
+	return len(levels) == 0
+}
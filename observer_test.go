@@ -0,0 +1,108 @@
+package mutable
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMutable_OnChange(t *testing.T) {
+	var tst = &TestE{
+		FieldA: "one",
+		FieldD: []*TestD{{ID: 1}},
+		FieldG: map[string]*TestC{"x": {FieldA: "x"}},
+	}
+	tst.ResetMutableState(tst)
+	var all, nested, fieldA []ChangedField
+	tst.OnChange("**", func(c ChangedField) { all = append(all, c) })
+	tst.OnChange("field/c/*", func(c ChangedField) { nested = append(nested, c) })
+	unsubscribe := tst.OnChange("field_a", func(c ChangedField) { fieldA = append(fieldA, c) })
+
+	// SetValue
+	assert.NoError(t, tst.SetValue("field_a", "two"))
+	assert.NoError(t, tst.SetValue("field/c/field_a", "c"))
+	assert.Error(t, tst.SetValue("unknown", "c"))
+	assert.Len(t, all, 2)
+	assert.Equal(t, []ChangedField{{Name: "field/c/field_a", OldValue: "", NewValue: "c"}}, nested)
+	assert.Equal(t, []ChangedField{{Name: "field_a", OldValue: "one", NewValue: "two"}}, fieldA)
+
+	// AnalyzeChanges notifies about newly detected changes only
+	all, nested, fieldA = nil, nil, nil
+	tst.FieldG["x"].FieldA = "y"
+	tst.AnalyzeChanges()
+	paths := func(changes []ChangedField) []string {
+		var result []string
+		for _, c := range changes {
+			result = append(result, c.Name)
+		}
+		return result
+	}
+	// Changes set with SetValue have been reported already
+	assert.Equal(t, []string{"field_g", "field_g/x", "field_g/x/field_a"}, paths(all))
+	assert.Empty(t, nested)
+	assert.Empty(t, fieldA)
+
+	// A field set with SetValue is reported again if it's changed directly
+	all, nested, fieldA = nil, nil, nil
+	tst.FieldC.FieldA = "d"
+	tst.AnalyzeChangesForce()
+	assert.Equal(t, []string{"field/c", "field/c/field_a"}, paths(all))
+	assert.Equal(t, "d", nested[0].NewValue)
+	assert.Empty(t, fieldA)
+
+	all, nested, fieldA = nil, nil, nil
+	unsubscribe()
+	tst.FieldA = "three"
+	tst.FieldD[0].FieldA = "d"
+	tst.AnalyzeChangesForce()
+	assert.Equal(t, []string{"field_a", "field_d", "field_d/0", "field_d/0/field_a"}, paths(all))
+	assert.Equal(t, "three", all[0].NewValue)
+	assert.Empty(t, nested)
+	assert.Empty(t, fieldA)
+
+	// Reset forgets reported changes
+	all = nil
+	tst.ResetMutableState(tst)
+	tst.FieldA = "four"
+	tst.AnalyzeChanges()
+	assert.Equal(t, []string{"field_a"}, paths(all))
+}
+
+func TestMatchLevels(t *testing.T) {
+	testCases := []struct {
+		pattern, path []string
+		expected      bool
+	}{
+		{[]string{"a"}, []string{"a"}, true},
+		{[]string{"a"}, []string{"a", "b"}, false},
+		{[]string{"*"}, []string{"abc"}, true},
+		{[]string{"a", "*"}, []string{"a", "b"}, true},
+		{[]string{"a", "b*"}, []string{"a", "c"}, false},
+		{[]string{"**"}, []string{}, true},
+		{[]string{"a", "**"}, []string{"a", "b", "c"}, true},
+		{[]string{"a", "**", "c"}, []string{"a", "c"}, true},
+		{[]string{"a", "**", "c"}, []string{"a", "b", "d"}, false},
+		{[]string{"["}, []string{"a"}, false},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, matchLevels(tc.pattern, tc.path), "%v %v", tc.pattern, tc.path)
+	}
+}
+
+func TestMutable_OnChange_keyedSlice(t *testing.T) {
+	var tst = &TestE{FieldD: []*TestD{{ID: 7}, {ID: 9}}}
+	tst.ResetMutableState(tst)
+	var all []string
+	tst.OnChange("**", func(c ChangedField) { all = append(all, c.Name) })
+
+	// Elements set with SetValue are addressed by indexes and not reported by AnalyzeChanges again
+	assert.NoError(t, tst.SetValue("field_d/1/field_a", "x"))
+	tst.AnalyzeChanges()
+	assert.Equal(t, []string{"field_d/1/field_a"}, all)
+
+	// Elements are reported by their current indexes instead of key values
+	all = nil
+	tst.FieldD = []*TestD{{ID: 5}, {ID: 7, FieldA: "y"}, tst.FieldD[1]}
+	tst.AnalyzeChangesForce()
+	assert.Equal(t, []string{"field_d", "field_d/0", "field_d/1", "field_d/1/field_a", "field_d/2", "field_d/2/field_a"}, all)
+}
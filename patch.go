@@ -0,0 +1,282 @@
+package mutable
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// JSON Patch operations (RFC 6902)
+const (
+	OpAdd     = "add"
+	OpRemove  = "remove"
+	OpReplace = "replace"
+	OpMove    = "move"
+	OpCopy    = "copy"
+	OpTest    = "test"
+)
+
+// PatchOperation is an operation of a JSON Patch document (RFC 6902)
+type PatchOperation struct {
+	Op    string      `json:"op"`              // Operation name
+	Path  string      `json:"path"`            // JSON Pointer (RFC 6901) to a target location
+	From  string      `json:"from,omitempty"`  // JSON Pointer to a source location of move and copy operations
+	Value interface{} `json:"value,omitempty"` // Value of add, replace and test operations
+}
+
+// MarshalJSON implements json.Marshaler interface for PatchOperation.
+// Value is kept for operations requiring it even if it's null
+func (o PatchOperation) MarshalJSON() ([]byte, error) {
+	type operation PatchOperation
+	switch o.Op {
+	case OpAdd, OpReplace, OpTest:
+		return json.Marshal(struct {
+			operation
+			Value interface{} `json:"value"`
+		}{operation(o), o.Value})
+	}
+	return json.Marshal(operation(o))
+}
+
+// JSONPatch is a JSON Patch document (RFC 6902)
+type JSONPatch []PatchOperation
+
+// JSONPatch returns a JSON Patch document which transforms an original state into a current one.
+// Paths are built of JSON names of struct fields, slice indexes and map keys.
+// Slice elements are removed in descending order of original indexes and added in ascending order of current ones,
+// so operations are valid being applied sequentially
+func (c ChangedFields) JSONPatch() JSONPatch {
+	patch := JSONPatch{}
+	c.jsonPatch("", &patch)
+	return patch
+}
+
+// jsonPatch appends operations of c struct fields changes with a path prefix to a patch
+func (c ChangedFields) jsonPatch(prefix string, patch *JSONPatch) {
+	for _, name := range c.sortedNames() {
+		field := c[name]
+		name = field.JSONName
+		if len(name) == 0 {
+			name = field.Name
+		}
+		field.jsonPatch(prefix+"/"+escapePointer(name), patch)
+	}
+}
+
+// jsonPatch appends operations of c changes with a given path to a patch
+func (c *ChangedField) jsonPatch(path string, patch *JSONPatch) {
+	switch {
+	case c.kind != reflect.Invalid && c.originalNil:
+		// Elements can't be added to a null collection, so a whole collection is added
+		*patch = append(*patch, PatchOperation{Op: OpAdd, Path: path, Value: c.current})
+	case c.kind != reflect.Invalid && c.currentNil:
+		*patch = append(*patch, PatchOperation{Op: OpReplace, Path: path, Value: nil})
+	case c.kind == reflect.Slice:
+		c.NestedFields.sliceJSONPatch(path, patch)
+	case c.kind == reflect.Map:
+		c.NestedFields.mapJSONPatch(path, patch)
+	case len(c.NestedFields) > 0:
+		c.NestedFields.jsonPatch(path, patch)
+	case c.OldValue == nil:
+		*patch = append(*patch, PatchOperation{Op: OpAdd, Path: path, Value: c.NewValue})
+	default:
+		// Nil values are kept within a document as null values
+		*patch = append(*patch, PatchOperation{Op: OpReplace, Path: path, Value: c.NewValue})
+	}
+}
+
+// sliceJSONPatch appends operations of c slice elements changes to a patch.
+// Removed, moved and replaced elements are removed first, then they are added at their current indexes,
+// at last changes of elements kept at their places are appended
+func (c ChangedFields) sliceJSONPatch(path string, patch *JSONPatch) {
+	var removed, added, changed []*ChangedField
+	for _, field := range c {
+		switch {
+		case field.Status == Removed:
+			removed = append(removed, field)
+		case field.Status == Added:
+			added = append(added, field)
+		case field.Status == Moved || len(field.NestedFields) == 0:
+			removed = append(removed, field)
+			added = append(added, field)
+		default:
+			changed = append(changed, field)
+		}
+	}
+	sort.Slice(removed, func(i, j int) bool { return removed[i].OldIndex > removed[j].OldIndex })
+	sort.Slice(added, func(i, j int) bool { return added[i].NewIndex < added[j].NewIndex })
+	sort.Slice(changed, func(i, j int) bool { return changed[i].NewIndex < changed[j].NewIndex })
+	for _, field := range removed {
+		*patch = append(*patch, PatchOperation{Op: OpRemove, Path: path + "/" + strconv.Itoa(field.OldIndex)})
+	}
+	for _, field := range added {
+		*patch = append(*patch, PatchOperation{Op: OpAdd, Path: path + "/" + strconv.Itoa(field.NewIndex), Value: field.NewValue})
+	}
+	for _, field := range changed {
+		field.NestedFields.jsonPatch(path+"/"+strconv.Itoa(field.NewIndex), patch)
+	}
+}
+
+// mapJSONPatch appends operations of c map elements changes to a patch
+func (c ChangedFields) mapJSONPatch(path string, patch *JSONPatch) {
+	for _, key := range c.sortedNames() {
+		field := c[key]
+		elemPath := path + "/" + escapePointer(key)
+		switch {
+		case field.Status == Removed:
+			*patch = append(*patch, PatchOperation{Op: OpRemove, Path: elemPath})
+		case field.Status == Added:
+			*patch = append(*patch, PatchOperation{Op: OpAdd, Path: elemPath, Value: field.NewValue})
+		case len(field.NestedFields) > 0:
+			field.NestedFields.jsonPatch(elemPath, patch)
+		default:
+			*patch = append(*patch, PatchOperation{Op: OpReplace, Path: elemPath, Value: field.NewValue})
+		}
+	}
+}
+
+// sortedNames returns sorted names of c fields to get operations in a stable order
+func (c ChangedFields) sortedNames() []string {
+	names := make([]string, 0, len(c))
+	for name := range c {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// pointerEscaper escapes JSON Pointer reference tokens
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// escapePointer returns an escaped JSON Pointer reference token
+func escapePointer(token string) string {
+	return pointerEscaper.Replace(token)
+}
+
+// patchOperation is a decoded operation of a JSON Patch document. Value is kept raw to parse it into a target type
+type patchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+// ApplyJSONPatch applies a JSON Patch document (RFC 6902) to a target object.
+// Paths are resolved by JSON names of struct fields, slice indexes and map keys.
+// A patch is applied atomically: if any operation fails, a target object is left untouched
+func (m *Mutable) ApplyJSONPatch(patch []byte) error {
+	if m.target == nil {
+		return errNoTarget
+	}
+	var operations []patchOperation
+	if err := json.Unmarshal(patch, &operations); err != nil {
+		return errInvalidPatch(err)
+	}
+	target := reflect.ValueOf(m.target).Elem()
+	// Try to apply a patch to a deep copy of a target first
+	if err := applyJSONPatch(deepCopy(target), operations); err != nil {
+		return err
+	}
+	if err := applyJSONPatch(target, operations); err != nil {
+		return err
+	}
+	// Invalidate analyzed changes of a target object and nested objects along paths
+	m.analyzed = false
+	for _, operation := range operations {
+		for _, pointer := range []string{operation.Path, operation.From} {
+			if tokens, err := parsePointer(pointer); err == nil {
+				invalidatePath(target, tokens)
+			}
+		}
+	}
+	return nil
+}
+
+// applyJSONPatch applies operations to v sequentially
+func applyJSONPatch(v reflect.Value, operations []patchOperation) error {
+	for i, operation := range operations {
+		if err := applyPatchOperation(v, operation); err != nil {
+			return errPatchOperation(i, operation.Op, operation.Path, err)
+		}
+	}
+	return nil
+}
+
+// applyPatchOperation applies a single operation to v
+func applyPatchOperation(v reflect.Value, operation patchOperation) error {
+	path, err := parsePointer(operation.Path)
+	if err != nil {
+		return err
+	}
+	var from []string
+	switch operation.Op {
+	case OpAdd, OpReplace, OpTest:
+		if operation.Value == nil {
+			return errMissingValue
+		}
+	case OpMove, OpCopy:
+		if from, err = parsePointer(operation.From); err != nil {
+			return err
+		}
+	}
+
+	switch operation.Op {
+	case OpAdd:
+		return walkPath(v, path, setPathValue(jsonValue(operation.Value), true))
+	case OpRemove:
+		return walkPath(v, path, removePathValue)
+	case OpReplace:
+		return walkPath(v, path, setPathValue(jsonValue(operation.Value), false))
+	case OpMove, OpCopy:
+		src, err := pathValue(v, from)
+		if err != nil {
+			return err
+		}
+		if operation.Op == OpMove {
+			if strings.HasPrefix(operation.Path+"/", operation.From+"/") {
+				if operation.Path == operation.From {
+					return nil
+				}
+				return errMoveToChild
+			}
+			if err := walkPath(v, from, removePathValue); err != nil {
+				return err
+			}
+		}
+		return walkPath(v, path, setPathValue(copiedValue(src), true))
+	case OpTest:
+		value, err := pathValue(v, path)
+		if err != nil {
+			return err
+		}
+		if !isJSONEqual(value, operation.Value) {
+			return errTestFailed
+		}
+		return nil
+	}
+	return errUnsupportedOp(operation.Op)
+}
+
+// pathValue returns a deep copy of a value with a given path within v
+func pathValue(v reflect.Value, path []string) (reflect.Value, error) {
+	value, err := lookupPath(v, path)
+	if err != nil {
+		return value, err
+	}
+	return deepCopy(value), nil
+}
+
+// isJSONEqual reports whether JSON representation of v is equal to a raw JSON value
+func isJSONEqual(v reflect.Value, raw []byte) bool {
+	encoded, err := json.Marshal(v.Interface())
+	if err != nil {
+		return false
+	}
+	var actual, expected interface{}
+	if json.Unmarshal(encoded, &actual) != nil || json.Unmarshal(raw, &expected) != nil {
+		return false
+	}
+	return reflect.DeepEqual(actual, expected)
+}
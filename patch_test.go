@@ -0,0 +1,223 @@
+package mutable
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type TestE struct {
+	Mutable
+	FieldA string            `json:"field_a"`
+	FieldB *TestB            `json:"field_b"`
+	FieldC TestB             `json:"field/c" mutable:"deep"`
+	FieldD []*TestD          `json:"field_d" mutable:"key=id"`
+	FieldE []TestC           `json:"field_e"`
+	FieldF map[string]string `json:"field_f"`
+	FieldG map[string]*TestC `json:"field_g"`
+}
+
+func TestChangedFields_JSONPatch(t *testing.T) {
+	var tst = &TestE{
+		FieldA: "one",
+		FieldB: &TestB{FieldA: "a"},
+		FieldD: []*TestD{{ID: 1}, {ID: 2}, {ID: 3}},
+		FieldE: []TestC{{FieldA: "a"}, {FieldA: "b"}, {FieldA: "c"}},
+		FieldF: map[string]string{"a": "1", "b": "2"},
+		FieldG: map[string]*TestC{"x": {FieldA: "x"}},
+	}
+	tst.ResetMutableState(tst)
+	tst.FieldA = "two"
+	tst.FieldB = nil
+	tst.FieldC.FieldA = "c"
+	tst.FieldD = []*TestD{{ID: 3}, {ID: 1, FieldA: "changed"}, {ID: 4}}
+	tst.FieldE = []TestC{{FieldA: "z"}, {FieldA: "b"}, {FieldA: "d"}, {FieldA: "e"}}
+	tst.FieldF["a"] = "3"
+	delete(tst.FieldF, "b")
+	tst.FieldF["c/d"] = "4"
+	tst.FieldG["x"].FieldA = "y"
+
+	patch := tst.AnalyzeChanges().JSONPatch()
+	assert.Contains(t, patch, PatchOperation{Op: OpReplace, Path: "/field_a", Value: "two"})
+	assert.Contains(t, patch, PatchOperation{Op: OpReplace, Path: "/field_b"})
+	assert.Contains(t, patch, PatchOperation{Op: OpReplace, Path: "/field~1c/field_a", Value: "c"})
+	assert.Contains(t, patch, PatchOperation{Op: OpReplace, Path: "/field_f/a", Value: "3"})
+	assert.Contains(t, patch, PatchOperation{Op: OpRemove, Path: "/field_f/b"})
+	assert.Contains(t, patch, PatchOperation{Op: OpAdd, Path: "/field_f/c~1d", Value: "4"})
+	assert.Contains(t, patch, PatchOperation{Op: OpReplace, Path: "/field_g/x/field_a", Value: "y"})
+
+	// Operations applied sequentially to an original document must produce a current one
+	var original, current interface{}
+	json.Unmarshal(marshal(tst.originalState), &original)
+	json.Unmarshal(marshal(tst), &current)
+	var ops []struct {
+		Op    string
+		Path  string
+		Value interface{}
+	}
+	json.Unmarshal(marshal(patch), &ops)
+	for _, op := range ops {
+		original = applyTestOperation(t, original, op.Op, strings.Split(op.Path, "/")[1:], op.Value)
+	}
+	assert.Equal(t, current, original)
+
+	assert.Equal(t, `{"op":"replace","path":"/field_b","value":null}`, string(marshal(PatchOperation{Op: OpReplace, Path: "/field_b"})))
+	assert.Equal(t, `{"op":"remove","path":"/field_b"}`, string(marshal(PatchOperation{Op: OpRemove, Path: "/field_b"})))
+
+	// No changes
+	assert.Empty(t, ChangedFields{}.JSONPatch())
+}
+
+func TestChangedFields_JSONPatch_nilCollections(t *testing.T) {
+	var tst = &TestE{FieldF: map[string]string{"a": "1"}}
+	assert.NoError(t, tst.ResetMutableState(tst))
+	original := marshal(tst)
+	tst.FieldD = []*TestD{{ID: 1}}
+	tst.FieldG = map[string]*TestC{"a": {FieldA: "a"}}
+	tst.FieldF = nil
+	patch := tst.AnalyzeChanges().JSONPatch()
+	assert.Equal(t, `[{"op":"add","path":"/field_d","value":[{"id":1,"field_a":""}]},`+
+		`{"op":"replace","path":"/field_f","value":null},`+
+		`{"op":"add","path":"/field_g","value":{"a":{"field_a":"a","field_b":null}}}]`, string(marshal(patch)))
+
+	// A patch is valid for an original document
+	var doc, current interface{}
+	json.Unmarshal(original, &doc)
+	json.Unmarshal(marshal(tst), &current)
+	var ops []struct {
+		Op    string
+		Path  string
+		Value interface{}
+	}
+	json.Unmarshal(marshal(patch), &ops)
+	for _, op := range ops {
+		doc = applyTestOperation(t, doc, op.Op, strings.Split(op.Path, "/")[1:], op.Value)
+	}
+	assert.Equal(t, current, doc)
+}
+
+// marshal returns JSON of v
+func marshal(v interface{}) []byte {
+	data, _ := json.Marshal(v)
+	return data
+}
+
+// applyTestOperation applies add, remove or replace operation to a decoded JSON document
+func applyTestOperation(t *testing.T, doc interface{}, op string, path []string, value interface{}) interface{} {
+	token := strings.NewReplacer("~1", "/", "~0", "~").Replace(path[0])
+	switch node := doc.(type) {
+	case map[string]interface{}:
+		if len(path) > 1 {
+			node[token] = applyTestOperation(t, node[token], op, path[1:], value)
+		} else if op == OpRemove {
+			delete(node, token)
+		} else {
+			node[token] = value
+		}
+		return node
+	case []interface{}:
+		i, err := strconv.Atoi(token)
+		if !assert.NoError(t, err) || !assert.True(t, i <= len(node), "index out of range") {
+			return node
+		}
+		switch {
+		case len(path) > 1:
+			node[i] = applyTestOperation(t, node[i], op, path[1:], value)
+		case op == OpRemove:
+			node = append(node[:i], node[i+1:]...)
+		case op == OpAdd:
+			node = append(node[:i], append([]interface{}{value}, node[i:]...)...)
+		default:
+			node[i] = value
+		}
+		return node
+	}
+	t.Errorf("cannot apply operation to %v", doc)
+	return doc
+}
+
+func TestMutable_ApplyJSONPatch(t *testing.T) {
+	newTestE := func() *TestE {
+		tst := &TestE{
+			FieldA: "one",
+			FieldB: &TestB{FieldA: "a", FieldB: []int{1, 2}},
+			FieldD: []*TestD{{ID: 1}, {ID: 2}},
+			FieldE: []TestC{{FieldA: "a"}},
+			FieldF: map[string]string{"a": "1"},
+			FieldG: map[string]*TestC{"x": {FieldA: "x"}},
+		}
+		tst.ResetMutableState(tst)
+		return tst
+	}
+
+	tst := newTestE()
+	err := tst.ApplyJSONPatch([]byte(`[
+		{"op": "test", "path": "/field_a", "value": "one"},
+		{"op": "replace", "path": "/field_a", "value": "two"},
+		{"op": "add", "path": "/field_b/field_b/1", "value": 5},
+		{"op": "add", "path": "/field_b/field_b/-", "value": 6},
+		{"op": "remove", "path": "/field_b/field_b/0"},
+		{"op": "replace", "path": "/field~1c/field_a", "value": "c"},
+		{"op": "move", "path": "/field_d/0", "from": "/field_d/1"},
+		{"op": "copy", "path": "/field_e/1", "from": "/field_e/0"},
+		{"op": "replace", "path": "/field_e/1/field_a", "value": "b"},
+		{"op": "add", "path": "/field_f/b", "value": "2"},
+		{"op": "remove", "path": "/field_f/a"},
+		{"op": "replace", "path": "/field_g/x/field_a", "value": "y"},
+		{"op": "test", "path": "/field_g/x", "value": {"field_a": "y", "field_b": null}}
+	]`))
+	if assert.NoError(t, err) {
+		assert.Equal(t, "two", tst.FieldA)
+		assert.Equal(t, []int{5, 2, 6}, tst.FieldB.FieldB)
+		assert.Equal(t, "c", tst.FieldC.FieldA)
+		assert.Equal(t, 2, tst.FieldD[0].ID)
+		assert.Equal(t, 1, tst.FieldD[1].ID)
+		assert.Equal(t, "a", tst.FieldE[0].FieldA)
+		assert.Equal(t, "b", tst.FieldE[1].FieldA)
+		assert.Equal(t, map[string]string{"b": "2"}, tst.FieldF)
+		assert.Equal(t, "y", tst.FieldG["x"].FieldA)
+		assert.Len(t, tst.AnalyzeChanges(), 7)
+	}
+
+	// Generated patch applied to an original object gives a current one
+	original := newTestE()
+	patch, _ := json.Marshal(tst.AnalyzeChanges().JSONPatch())
+	if assert.NoError(t, original.ApplyJSONPatch(patch)) {
+		assert.Equal(t, string(marshal(tst)), string(marshal(original)))
+	}
+
+	// Failed patches leave an object untouched
+	for _, patch := range []string{
+		`[{"op": "replace", "path": "/field_a", "value": "two"}, {"op": "test", "path": "/field_a", "value": "one"}]`,
+		`[{"op": "replace", "path": "/field_a", "value": "two"}, {"op": "replace", "path": "/unknown", "value": 1}]`,
+		`[{"op": "replace", "path": "/field_a", "value": "two"}, {"op": "remove", "path": "/field_d/5"}]`,
+		`[{"op": "replace", "path": "/field_a", "value": "two"}, {"op": "replace", "path": "/field_f/b", "value": "1"}]`,
+		`[{"op": "replace", "path": "/field_a", "value": "two"}, {"op": "add", "path": "/field_b/field_a", "value": {"a": 1}}]`,
+		`[{"op": "replace", "path": "/field_a", "value": "two"}, {"op": "move", "path": "/field_b/field_a", "from": "/field_b"}]`,
+		`[{"op": "replace", "path": "/field_a", "value": "two"}, {"op": "replace", "path": "/field_a"}]`,
+		`[{"op": "replace", "path": "/field_a", "value": "two"}, {"op": "unknown", "path": "/field_a"}]`,
+		`[{"op": "replace", "path": "field_a", "value": "two"}]`,
+		`{"op": "replace"}`,
+	} {
+		tst := newTestE()
+		assert.Error(t, tst.ApplyJSONPatch([]byte(patch)), patch)
+		assert.Equal(t, "one", tst.FieldA, patch)
+		assert.Empty(t, tst.AnalyzeChanges(), patch)
+	}
+
+	// Not initialized object
+	assert.Error(t, (&TestE{}).ApplyJSONPatch([]byte(`[]`)))
+}
+
+func TestParsePointer(t *testing.T) {
+	tokens, err := parsePointer("/a~1b/c~0d/~01/")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a/b", "c~d", "~1", ""}, tokens)
+	_, err = parsePointer("a")
+	assert.Error(t, err)
+	_, err = parsePointer("/a~2")
+	assert.Error(t, err)
+}
@@ -0,0 +1,626 @@
+package mutable
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// pathOp is an operation applied to a container (struct, slice, array or map) of the last path level
+type pathOp func(container reflect.Value, token string) error
+
+// walkPath goes down through v by path tokens and applies op to the container of the last token.
+// Pointers and interfaces are dereferenced. Map elements and interface values are not addressable,
+// so they are modified as addressable copies which are put back afterwards
+func walkPath(v reflect.Value, tokens []string, op pathOp) error {
+	for {
+		switch v.Kind() {
+		case reflect.Ptr:
+			if v.IsNil() {
+				return errNilPointer
+			}
+			v = v.Elem()
+			continue
+		case reflect.Interface:
+			if v.IsNil() {
+				return errNilPointer
+			}
+			elem := reflect.New(v.Elem().Type()).Elem()
+			elem.Set(v.Elem())
+			if err := walkPath(elem, tokens, op); err != nil {
+				return err
+			}
+			v.Set(elem)
+			return nil
+		}
+		break
+	}
+	if len(tokens) == 1 {
+		return op(v, tokens[0])
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		field, err := structField(v, tokens[0])
+		if err != nil {
+			return err
+		}
+		return walkPath(field, tokens[1:], op)
+	case reflect.Slice, reflect.Array:
+		i, err := elemIndex(v, tokens[0])
+		if err != nil {
+			return err
+		}
+		return walkPath(v.Index(i), tokens[1:], op)
+	case reflect.Map:
+		key, err := mapKey(v.Type().Key(), tokens[0])
+		if err != nil {
+			return err
+		}
+		value := v.MapIndex(key)
+		if !value.IsValid() {
+			return errPathNotFound
+		}
+		elem := reflect.New(value.Type()).Elem()
+		elem.Set(value)
+		if err := walkPath(elem, tokens[1:], op); err != nil {
+			return err
+		}
+		v.SetMapIndex(key, elem)
+		return nil
+	}
+	return errPathNotFound
+}
+
+// splitPath parses a path with a syntax and resolves its levels against v into tokens (see resolvePath).
+// It reports false if a path can't be parsed or resolved
+func splitPath(v reflect.Value, path string, syntax PathSyntax, allocate bool) ([]string, bool) {
+	levels, err := syntax.Parse(path)
+	if err != nil || len(levels) == 0 {
+		return nil, false
+	}
+	return resolvePath(v, levels, allocate)
+}
+
+// resolvePath resolves path levels against v into tokens: JSON names of struct fields, slice and array indexes
+// and map keys. Struct field names and map keys may contain a separator of joinable levels,
+// so every possible name made of joined levels is tried.
+// If allocate is true, a path may go through nil pointers, nil maps, new map keys and a new slice element
+// appended to the end of a slice (see allocPath), such levels are resolved against zero values of their types.
+// It reports false if a path can't be resolved
+func resolvePath(v reflect.Value, levels []PathLevel, allocate bool) ([]string, bool) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.Kind() == reflect.Ptr && v.IsNil() && allocate {
+			v = reflect.Zero(v.Type().Elem())
+			continue
+		}
+		v = v.Elem()
+	}
+	names := joinedNames(levels)
+	// All levels may be joined into a single name
+	whole, isWhole := names[len(names)-1], len(names) == len(levels)
+	switch v.Kind() {
+	case reflect.Struct:
+		info := getTypeInfo(v.Type())
+		if _, ok := info.byJSONName[whole]; ok && isWhole {
+			return []string{whole}, true
+		}
+		return resolveLevels(levels, names, allocate, func(name string) (reflect.Value, bool) {
+			field, ok := info.byJSONName[name]
+			if !ok {
+				return reflect.Value{}, false
+			}
+			return v.Field(field.index), true
+		})
+	case reflect.Slice, reflect.Array:
+		name := levels[0].Name
+		var elem reflect.Value
+		if i, err := elemIndex(v, name); err == nil {
+			elem = v.Index(i)
+		} else if allocate && v.Kind() == reflect.Slice && name == strconv.Itoa(v.Len()) {
+			// A new element appended to the end of a slice
+			elem = reflect.Zero(v.Type().Elem())
+		} else {
+			return nil, false
+		}
+		if len(levels) == 1 {
+			return []string{name}, true
+		}
+		if tokens, ok := resolvePath(elem, levels[1:], allocate); ok {
+			return append([]string{name}, tokens...), true
+		}
+	case reflect.Map:
+		if key, err := mapKey(v.Type().Key(), whole); err == nil && isWhole && v.MapIndex(key).IsValid() {
+			return []string{whole}, true
+		}
+		tokens, ok := resolveLevels(levels, names, allocate, func(name string) (reflect.Value, bool) {
+			key, err := mapKey(v.Type().Key(), name)
+			if err != nil || !v.MapIndex(key).IsValid() {
+				return reflect.Value{}, false
+			}
+			return v.MapIndex(key), true
+		})
+		if !ok && allocate {
+			// Existing keys take precedence over new ones
+			tokens, ok = resolveLevels(levels, names, allocate, func(name string) (reflect.Value, bool) {
+				if _, err := mapKey(v.Type().Key(), name); err != nil {
+					return reflect.Value{}, false
+				}
+				return reflect.Zero(v.Type().Elem()), true
+			})
+		}
+		if !ok && isWhole {
+			if _, err := mapKey(v.Type().Key(), whole); err == nil {
+				// A new map element
+				return []string{whole}, true
+			}
+		}
+		return tokens, ok
+	}
+	return nil, false
+}
+
+// resolveLevels tries every name made of the first joined levels but the whole path as a level name.
+// A level value is returned by lookup, the rest of levels is resolved against it
+func resolveLevels(levels []PathLevel, names []string, allocate bool, lookup func(name string) (reflect.Value, bool)) ([]string, bool) {
+	for i, name := range names {
+		if i == len(levels)-1 {
+			break
+		}
+		if nested, ok := lookup(name); ok {
+			if tokens, ok := resolvePath(nested, levels[i+1:], allocate); ok {
+				return append([]string{name}, tokens...), true
+			}
+		}
+	}
+	return nil, false
+}
+
+// joinedNames returns names made of the first levels joined with their joiners: a name of the first level,
+// names of the first two levels joined and so on while levels are joinable
+func joinedNames(levels []PathLevel) []string {
+	names := []string{levels[0].Name}
+	for i := 1; i < len(levels) && len(levels[i-1].Joiner) > 0; i++ {
+		names = append(names, names[i-1]+levels[i-1].Joiner+levels[i].Name)
+	}
+	return names
+}
+
+// allocPath makes every level of path tokens within v but the last one exist: nil pointers and nil maps are allocated,
+// missing map elements are added and a slice element with an index equal to a slice length is appended.
+// A container of the last level is allocated as well, so a value may be set to it with setFieldValue
+func allocPath(v reflect.Value, tokens []string) error {
+	for {
+		switch v.Kind() {
+		case reflect.Ptr:
+			if v.IsNil() {
+				if !v.CanSet() {
+					return errNotSettable
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+			continue
+		case reflect.Interface:
+			if v.IsNil() {
+				return errNilPointer
+			}
+			elem := reflect.New(v.Elem().Type()).Elem()
+			elem.Set(v.Elem())
+			if err := allocPath(elem, tokens); err != nil {
+				return err
+			}
+			v.Set(elem)
+			return nil
+		case reflect.Map:
+			if v.IsNil() {
+				if !v.CanSet() {
+					return errNotSettable
+				}
+				v.Set(reflect.MakeMap(v.Type()))
+			}
+		}
+		break
+	}
+	if v.Kind() == reflect.Slice && tokens[0] == strconv.Itoa(v.Len()) {
+		if !v.CanSet() {
+			return errNotSettable
+		}
+		v.Set(reflect.Append(v, reflect.Zero(v.Type().Elem())))
+	}
+	if len(tokens) == 1 {
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		field, err := structField(v, tokens[0])
+		if err != nil {
+			return err
+		}
+		return allocPath(field, tokens[1:])
+	case reflect.Slice, reflect.Array:
+		i, err := elemIndex(v, tokens[0])
+		if err != nil {
+			return err
+		}
+		return allocPath(v.Index(i), tokens[1:])
+	case reflect.Map:
+		key, err := mapKey(v.Type().Key(), tokens[0])
+		if err != nil {
+			return err
+		}
+		elem := reflect.New(v.Type().Elem()).Elem()
+		if value := v.MapIndex(key); value.IsValid() {
+			elem.Set(value)
+		}
+		if err := allocPath(elem, tokens[1:]); err != nil {
+			return err
+		}
+		v.SetMapIndex(key, elem)
+		return nil
+	}
+	return errPathNotFound
+}
+
+// invalidatePath clears analyzed flags of v and of every mutable object along path tokens within v
+// including a value of the last token, as their cached changes are outdated after a value is changed
+func invalidatePath(v reflect.Value, tokens []string) {
+	for i := 0; ; i++ {
+		for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+			v = v.Elem()
+		}
+		if mf := mutableField(v); mf.CanSet() {
+			mf.Addr().Interface().(*Mutable).analyzed = false
+		}
+		if i == len(tokens) {
+			return
+		}
+		var err error
+		if v, err = getPathValue(v, tokens[i]); err != nil {
+			return
+		}
+	}
+}
+
+// pathType returns a type of a value with given path tokens within a value of type t
+func pathType(t reflect.Type, tokens []string) (reflect.Type, error) {
+	for _, token := range tokens {
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		switch t.Kind() {
+		case reflect.Struct:
+			field, ok := getTypeInfo(t).byJSONName[token]
+			if !ok {
+				return nil, errPathNotFound
+			}
+			t = t.Field(field.index).Type
+		case reflect.Slice, reflect.Array, reflect.Map:
+			t = t.Elem()
+		default:
+			return nil, errPathNotFound
+		}
+	}
+	return t, nil
+}
+
+// setFieldValue returns an operation which sets a value to a container element (see SetValue for supported values).
+// Pointers are dereferenced unless a value is a pointer of the same type, new map elements are added.
+// If allocate is true, a nil pointer element is allocated
+func setFieldValue(value interface{}, allocate bool) pathOp {
+	return func(container reflect.Value, token string) error {
+		switch container.Kind() {
+		case reflect.Struct:
+			field, err := structField(container, token)
+			if err != nil {
+				return err
+			}
+			return setToField(field, value, allocate)
+		case reflect.Slice, reflect.Array:
+			i, err := elemIndex(container, token)
+			if err != nil {
+				return err
+			}
+			return setToField(container.Index(i), value, allocate)
+		case reflect.Map:
+			key, err := mapKey(container.Type().Key(), token)
+			if err != nil {
+				return err
+			}
+			// Map elements are not addressable, so set a value to a copy and put it back
+			elem := reflect.New(container.Type().Elem()).Elem()
+			if existing := container.MapIndex(key); existing.IsValid() {
+				elem.Set(existing)
+			} else if elem.Kind() == reflect.Ptr {
+				elem.Set(reflect.New(elem.Type().Elem()))
+			}
+			if err := setToField(elem, value, allocate); err != nil {
+				return err
+			}
+			if container.IsNil() {
+				return errNilMap
+			}
+			container.SetMapIndex(key, elem)
+			return nil
+		}
+		return errPathNotFound
+	}
+}
+
+// setToField sets a value to a field, a pointer field is dereferenced unless a value is a pointer of the same type.
+// If allocate is true, a nil pointer field is set to a new object having a value
+func setToField(field reflect.Value, value interface{}, allocate bool) error {
+	if field.Kind() == reflect.Ptr && reflect.TypeOf(value) != field.Type() {
+		if field.IsNil() && allocate {
+			// Set a value to a new object first to keep a field untouched on error
+			elem := reflect.New(field.Type().Elem())
+			if err := trySetValueToField(elem.Elem(), value); err != nil {
+				return err
+			}
+			if !field.CanSet() {
+				return errNotSettable
+			}
+			field.Set(elem)
+			return nil
+		}
+		field = field.Elem()
+	}
+	return trySetValueToField(field, value)
+}
+
+// allocSetValue allocates missing levels of path tokens within v (see allocPath) and sets a value to the last level.
+// A value is checked against a new field first, so a path is not allocated for an unsuitable value
+func allocSetValue(v reflect.Value, tokens []string, value interface{}) error {
+	fieldType, err := pathType(v.Type(), tokens)
+	if err != nil {
+		return err
+	}
+	if err := setToField(reflect.New(fieldType).Elem(), value, true); err != nil {
+		return err
+	}
+	if err := allocPath(v, tokens); err != nil {
+		return err
+	}
+	return walkPath(v, tokens, setFieldValue(value, true))
+}
+
+// structField returns a field of a struct v by its JSON name
+func structField(v reflect.Value, name string) (reflect.Value, error) {
+	field, ok := getTypeInfo(v.Type()).byJSONName[name]
+	if !ok || !v.Field(field.index).CanInterface() {
+		return reflect.Value{}, errPathNotFound
+	}
+	return v.Field(field.index), nil
+}
+
+// elemIndex returns an index of an existing slice or array element
+func elemIndex(v reflect.Value, token string) (int, error) {
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || i >= v.Len() || token != strconv.Itoa(i) {
+		return 0, errIndexOutOfRange(token)
+	}
+	return i, nil
+}
+
+// mapKey returns a map key of type t parsed from a path token
+func mapKey(t reflect.Type, token string) (reflect.Value, error) {
+	if t.Kind() == reflect.String {
+		return reflect.ValueOf(token).Convert(t), nil
+	}
+	key, err := parseValue([]byte(token), t)
+	if err != nil {
+		return reflect.Value{}, errInvalidMapKey(token)
+	}
+	return reflect.ValueOf(key), nil
+}
+
+// getPathValue returns a value of a container element with a given token
+func getPathValue(container reflect.Value, token string) (reflect.Value, error) {
+	switch container.Kind() {
+	case reflect.Struct:
+		return structField(container, token)
+	case reflect.Slice, reflect.Array:
+		i, err := elemIndex(container, token)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return container.Index(i), nil
+	case reflect.Map:
+		key, err := mapKey(container.Type().Key(), token)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		if value := container.MapIndex(key); value.IsValid() {
+			return value, nil
+		}
+	}
+	return reflect.Value{}, errPathNotFound
+}
+
+// lookupPath returns a value with a given path within v
+func lookupPath(v reflect.Value, path []string) (reflect.Value, error) {
+	var value reflect.Value
+	err := walkPath(v, path, func(container reflect.Value, token string) error {
+		var err error
+		value, err = getPathValue(container, token)
+		return err
+	})
+	return value, err
+}
+
+// valueProvider returns a value of a given type to set it to a path
+type valueProvider func(t reflect.Type) (reflect.Value, error)
+
+// jsonValue returns a provider of a value parsed from raw JSON
+func jsonValue(raw []byte) valueProvider {
+	return func(t reflect.Type) (reflect.Value, error) {
+		value, err := parseValue(raw, t)
+		if err != nil {
+			return reflect.Value{}, errCannotParse
+		}
+		v := reflect.New(t).Elem()
+		if value != nil {
+			v.Set(reflect.ValueOf(value))
+		}
+		return v, nil
+	}
+}
+
+// copiedValue returns a provider of a deep copy of src. If src is not assignable to a requested type,
+// it's converted through JSON
+func copiedValue(src reflect.Value) valueProvider {
+	return func(t reflect.Type) (reflect.Value, error) {
+		if src.Type().AssignableTo(t) {
+			return deepCopy(src), nil
+		}
+		raw, err := json.Marshal(src.Interface())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return jsonValue(raw)(t)
+	}
+}
+
+// setPathValue returns an operation which replaces an existing container element with a provided value.
+// If insert is true, a value is inserted to a slice (token "-" means the end of a slice) and may be added to a map
+func setPathValue(provide valueProvider, insert bool) pathOp {
+	return func(container reflect.Value, token string) error {
+		switch container.Kind() {
+		case reflect.Struct:
+			field, err := structField(container, token)
+			if err != nil {
+				return err
+			}
+			return setProvidedValue(field, provide)
+		case reflect.Slice:
+			if insert {
+				i := container.Len()
+				if token != "-" {
+					var err error
+					if i, err = strconv.Atoi(token); err != nil || i < 0 || i > container.Len() || token != strconv.Itoa(i) {
+						return errIndexOutOfRange(token)
+					}
+				}
+				elem, err := provide(container.Type().Elem())
+				if err != nil {
+					return err
+				}
+				if !container.CanSet() {
+					return errNotSettable
+				}
+				container.Set(reflect.Append(container, elem))
+				reflect.Copy(container.Slice(i+1, container.Len()), container.Slice(i, container.Len()-1))
+				container.Index(i).Set(elem)
+				return nil
+			}
+			fallthrough
+		case reflect.Array:
+			i, err := elemIndex(container, token)
+			if err != nil {
+				return err
+			}
+			return setProvidedValue(container.Index(i), provide)
+		case reflect.Map:
+			key, err := mapKey(container.Type().Key(), token)
+			if err != nil {
+				return err
+			}
+			if !insert && !container.MapIndex(key).IsValid() {
+				return errPathNotFound
+			}
+			elem, err := provide(container.Type().Elem())
+			if err != nil {
+				return err
+			}
+			if container.IsNil() {
+				if !container.CanSet() {
+					return errNotSettable
+				}
+				container.Set(reflect.MakeMap(container.Type()))
+			}
+			container.SetMapIndex(key, elem)
+			return nil
+		}
+		return errPathNotFound
+	}
+}
+
+// setProvidedValue sets a provided value to a field
+func setProvidedValue(field reflect.Value, provide valueProvider) error {
+	if !field.CanSet() {
+		return errNotSettable
+	}
+	value, err := provide(field.Type())
+	if err != nil {
+		return err
+	}
+	field.Set(value)
+	return nil
+}
+
+// removePathValue removes a container element: a struct field is set to its zero value,
+// a slice element is deleted and a map key is deleted
+func removePathValue(container reflect.Value, token string) error {
+	switch container.Kind() {
+	case reflect.Struct:
+		field, err := structField(container, token)
+		if err != nil {
+			return err
+		}
+		if !field.CanSet() {
+			return errNotSettable
+		}
+		field.Set(reflect.Zero(field.Type()))
+		return nil
+	case reflect.Slice:
+		i, err := elemIndex(container, token)
+		if err != nil {
+			return err
+		}
+		if !container.CanSet() {
+			return errNotSettable
+		}
+		reflect.Copy(container.Slice(i, container.Len()), container.Slice(i+1, container.Len()))
+		container.Index(container.Len() - 1).Set(reflect.Zero(container.Type().Elem()))
+		container.Set(container.Slice(0, container.Len()-1))
+		return nil
+	case reflect.Map:
+		key, err := mapKey(container.Type().Key(), token)
+		if err != nil {
+			return err
+		}
+		if !container.MapIndex(key).IsValid() {
+			return errPathNotFound
+		}
+		container.SetMapIndex(key, reflect.Value{})
+		return nil
+	}
+	return errPathNotFound
+}
+
+// parsePointer returns unescaped reference tokens of a JSON Pointer (RFC 6901)
+func parsePointer(pointer string) ([]string, error) {
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, errInvalidPointer(pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		if strings.Contains(strings.NewReplacer("~0", "", "~1", "").Replace(token), "~") {
+			return nil, errInvalidPointer(pointer)
+		}
+		tokens[i] = pointerUnescaper.Replace(token)
+	}
+	return tokens, nil
+}
+
+// formatPointer returns a JSON Pointer of path tokens
+func formatPointer(tokens []string) string {
+	var pointer strings.Builder
+	for _, token := range tokens {
+		pointer.WriteString("/" + escapePointer(token))
+	}
+	return pointer.String()
+}
+
+// pointerUnescaper unescapes JSON Pointer reference tokens
+var pointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
@@ -0,0 +1,190 @@
+package mutable
+
+import (
+	"reflect"
+	"strings"
+)
+
+// PathLevel is a level of a parsed path of a field
+type PathLevel struct {
+	Name   string // JSON name of a struct field, slice or array index or map key
+	Joiner string // Separator joining a name with the next level name (empty if levels can't be joined)
+}
+
+// PathSyntax parses and formats paths of fields used by SetValue, GetValue and other path based methods.
+// Levels are joinable if a struct field name or a map key may contain a separator between them,
+// then every possible name made of joined levels is tried while a path is resolved against an object
+type PathSyntax interface {
+	// Parse splits a path into levels
+	Parse(path string) ([]PathLevel, error)
+	// Format returns a path of level names
+	Format(levels []string) string
+}
+
+var (
+	// JSONPointerPath is a path syntax of JSON Pointers (RFC 6901), eg. /cars/2/engine/price.
+	// Levels are escaped with ~0 and ~1, so they are never joined
+	JSONPointerPath PathSyntax = jsonPointerSyntax{}
+	// DotPath is a path syntax of dotted names with indexes and map keys within brackets, eg. cars[2].engine.price.
+	// Dotted levels may be joined, bracketed ones can't contain a closing bracket
+	DotPath PathSyntax = dotSyntax{}
+)
+
+// SeparatorPath returns a path syntax of levels separated by a separator, eg. cars/2/engine/price for "/".
+// Package var LevelSeparator is used if a separator is empty
+func SeparatorPath(separator string) PathSyntax {
+	return separatorSyntax{separator: separator}
+}
+
+// separatorSyntax is a path syntax of levels separated by a separator
+type separatorSyntax struct {
+	separator string // Separator of levels (LevelSeparator if it's empty)
+}
+
+// sep returns a separator of levels
+func (s separatorSyntax) sep() string {
+	if len(s.separator) == 0 {
+		return LevelSeparator
+	}
+	return s.separator
+}
+
+// Parse implements PathSyntax interface for separatorSyntax
+func (s separatorSyntax) Parse(path string) ([]PathLevel, error) {
+	sep := s.sep()
+	names := strings.Split(path, sep)
+	levels := make([]PathLevel, len(names))
+	for i, name := range names {
+		levels[i].Name = name
+		if i < len(names)-1 {
+			levels[i].Joiner = sep
+		}
+	}
+	return levels, nil
+}
+
+// Format implements PathSyntax interface for separatorSyntax
+func (s separatorSyntax) Format(levels []string) string {
+	return strings.Join(levels, s.sep())
+}
+
+// jsonPointerSyntax is a path syntax of JSON Pointers
+type jsonPointerSyntax struct{}
+
+// Parse implements PathSyntax interface for jsonPointerSyntax
+func (jsonPointerSyntax) Parse(path string) ([]PathLevel, error) {
+	tokens, err := parsePointer(path)
+	if err != nil {
+		return nil, err
+	}
+	levels := make([]PathLevel, len(tokens))
+	for i, token := range tokens {
+		levels[i].Name = token
+	}
+	return levels, nil
+}
+
+// Format implements PathSyntax interface for jsonPointerSyntax
+func (jsonPointerSyntax) Format(levels []string) string {
+	return formatPointer(levels)
+}
+
+// dotSyntax is a path syntax of dotted names with bracketed indexes and keys
+type dotSyntax struct{}
+
+// Parse implements PathSyntax interface for dotSyntax
+func (dotSyntax) Parse(path string) ([]PathLevel, error) {
+	var levels []PathLevel
+	for i := 0; ; {
+		// A name ends with a dot, a bracket or the end of a path, a path may start with a bracket
+		end := strings.IndexAny(path[i:], ".[")
+		switch {
+		case end < 0:
+			if i == len(path) {
+				return nil, errInvalidPath(path)
+			}
+			return append(levels, PathLevel{Name: path[i:]}), nil
+		case end > 0:
+			levels = append(levels, PathLevel{Name: path[i : i+end]})
+		case i > 0 || path[0] != '[':
+			return nil, errInvalidPath(path)
+		}
+		i += end
+		bracketed := false
+		for i < len(path) && path[i] == '[' {
+			closing := strings.IndexByte(path[i:], ']')
+			if closing < 0 {
+				return nil, errInvalidPath(path)
+			}
+			levels = append(levels, PathLevel{Name: path[i+1 : i+closing]})
+			i += closing + 1
+			bracketed = true
+		}
+		if i == len(path) {
+			return levels, nil
+		}
+		if path[i] != '.' {
+			return nil, errInvalidPath(path)
+		}
+		if !bracketed {
+			// Dotted names may be a single name containing a dot
+			levels[len(levels)-1].Joiner = "."
+		}
+		i++
+	}
+}
+
+// Format implements PathSyntax interface for dotSyntax
+func (dotSyntax) Format(levels []string) string {
+	var path strings.Builder
+	for i, level := range levels {
+		if len(level) == 0 || isDigits(level) || strings.ContainsAny(level, ".[]") {
+			path.WriteString("[" + level + "]")
+			continue
+		}
+		if i > 0 {
+			path.WriteByte('.')
+		}
+		path.WriteString(level)
+	}
+	return path.String()
+}
+
+// isDigits reports whether s consists of decimal digits only
+func isDigits(s string) bool {
+	return len(strings.TrimLeft(s, "0123456789")) == 0
+}
+
+// levelNames returns names of parsed path levels, an invalid path has no levels
+func levelNames(syntax PathSyntax, path string) []string {
+	levels, _ := syntax.Parse(path)
+	names := make([]string, len(levels))
+	for i, level := range levels {
+		names[i] = level.Name
+	}
+	return names
+}
+
+// pathOptions are options of path based methods
+type pathOptions struct {
+	syntax PathSyntax // Path syntax (LevelSeparator separated paths if it's nil)
+	strict bool       // Nil pointers, nil maps and missing elements along a path are not allocated
+}
+
+// pathSyntax returns a path syntax of options
+func (o pathOptions) pathSyntax() PathSyntax {
+	if o.syntax == nil {
+		return separatorSyntax{}
+	}
+	return o.syntax
+}
+
+// split parses a path and resolves it against v (see splitPath), missing levels are allowed unless paths are strict
+func (o pathOptions) split(v reflect.Value, path string) ([]string, bool) {
+	return splitPath(v, path, o.pathSyntax(), !o.strict)
+}
+
+// splitExisting parses a path and resolves it against existing levels of v (see splitPath)
+func (o pathOptions) splitExisting(v reflect.Value, path string) ([]string, bool) {
+	return splitPath(v, path, o.pathSyntax(), false)
+}
@@ -0,0 +1,104 @@
+package mutable
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPathSyntax_Parse(t *testing.T) {
+	for _, tc := range []struct {
+		syntax   PathSyntax
+		path     string
+		expected []PathLevel
+	}{
+		{SeparatorPath(""), "a/b", []PathLevel{{"a", "/"}, {"b", ""}}},
+		{SeparatorPath("::"), "a::b::c", []PathLevel{{"a", "::"}, {"b", "::"}, {"c", ""}}},
+		{JSONPointerPath, "/a~1b/~0c", []PathLevel{{"a/b", ""}, {"~c", ""}}},
+		{DotPath, "a.b", []PathLevel{{"a", "."}, {"b", ""}}},
+		{DotPath, "items[2].name", []PathLevel{{"items", ""}, {"2", ""}, {"name", ""}}},
+		{DotPath, "a.b[x.y][0].c", []PathLevel{{"a", "."}, {"b", ""}, {"x.y", ""}, {"0", ""}, {"c", ""}}},
+		{DotPath, "[a.b].c", []PathLevel{{"a.b", ""}, {"c", ""}}},
+	} {
+		levels, err := tc.syntax.Parse(tc.path)
+		if assert.NoError(t, err, tc.path) {
+			assert.Equal(t, tc.expected, levels, tc.path)
+		}
+	}
+	for _, path := range []string{"", "a.", ".a", "a..b", "a[0", "a[0]b", "a.[0]"} {
+		_, err := DotPath.Parse(path)
+		assert.Error(t, err, path)
+	}
+	_, err := JSONPointerPath.Parse("a/b")
+	assert.Error(t, err)
+}
+
+func TestPathSyntax_Format(t *testing.T) {
+	levels := []string{"items", "2", "a.b", "name"}
+	assert.Equal(t, "items/2/a.b/name", SeparatorPath("").Format(levels))
+	assert.Equal(t, "/items/2/a.b/name", JSONPointerPath.Format(levels))
+	assert.Equal(t, "items[2][a.b].name", DotPath.Format(levels))
+	for _, syntax := range []PathSyntax{JSONPointerPath, DotPath} {
+		parsed, err := syntax.Parse(syntax.Format(levels))
+		if assert.NoError(t, err) {
+			assert.Equal(t, levels, []string{parsed[0].Name, parsed[1].Name, parsed[2].Name, parsed[3].Name})
+		}
+	}
+}
+
+func TestMutable_SetPathSyntax(t *testing.T) {
+	var tst = &TestE{
+		FieldD: []*TestD{{ID: 1}},
+		FieldF: map[string]string{"a.b": "c", "d/e": "f"},
+	}
+	assert.NoError(t, tst.ResetMutableState(tst))
+
+	tst.SetPathSyntax(JSONPointerPath)
+	assert.NoError(t, tst.SetValue("/field~1c/field_a", "x"))
+	assert.NoError(t, tst.SetValue("/field_f/d~1e", "g"))
+	assert.True(t, IsCannotFindErr(tst.SetValue("field_a", "x")))
+	value, err := tst.GetValue("/field~1c/field_a")
+	assert.NoError(t, err)
+	assert.Equal(t, "x", value)
+
+	tst.SetPathSyntax(DotPath)
+	var paths []string
+	unsubscribe := tst.OnChange("field_d[*].*", func(c ChangedField) {
+		paths = append(paths, c.Name)
+	})
+	defer unsubscribe()
+	assert.NoError(t, tst.SetValue("field_d[0].field_a", "y"))
+	assert.NoError(t, tst.SetValue("field_f.a.b", "h"))
+	assert.NoError(t, tst.SetValue("field_f[a.b]", "i"))
+	assert.NoError(t, tst.SetValue("field_e[0].field_b", "[1]"))
+	assert.Equal(t, "y", tst.FieldD[0].FieldA)
+	assert.Equal(t, map[string]string{"a.b": "i", "d/e": "g"}, tst.FieldF)
+	assert.Equal(t, []int{1}, tst.FieldE[0].FieldB)
+	assert.NoError(t, tst.RevertField("field_e"))
+	assert.Empty(t, tst.FieldE)
+	assert.Equal(t, []string{"field_d[0].field_a"}, paths)
+
+	// Instances don't share a syntax
+	var other = &TestE{}
+	assert.NoError(t, other.ResetMutableState(other))
+	assert.NoError(t, other.SetValue("field/c/field_a", "z"))
+	assert.Equal(t, "z", other.FieldC.FieldA)
+
+	tst.SetPathSyntax(nil)
+	assert.NoError(t, tst.SetValue("field/c/field_a", "z"))
+	assert.Equal(t, "z", tst.FieldC.FieldA)
+}
+
+func TestDiff_UsePathSyntax(t *testing.T) {
+	old, new := TestE{}, TestE{FieldA: "a", FieldC: TestB{FieldA: "b", FieldB: []int{1}}}
+	changes, err := Diff(old, new, UsePathSyntax(DotPath), IgnoreFields("FieldC.FieldA", "FieldA"))
+	assert.NoError(t, err)
+	assert.Len(t, changes, 1)
+	assert.Len(t, changes["FieldC"].NestedFields, 1)
+
+	// JSON Pointer paths of JSON names
+	changes, err = Diff(old, new, UsePathSyntax(JSONPointerPath), IgnoreFields("/field~1c/field_a", "/field_a"))
+	assert.NoError(t, err)
+	assert.Len(t, changes, 1)
+	assert.Len(t, changes["FieldC"].NestedFields, 1)
+}
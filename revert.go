@@ -0,0 +1,99 @@
+package mutable
+
+import (
+	"reflect"
+)
+
+// Revert restores a target object to its original state.
+// Nested mutable objects are reset with restored values
+func (m *Mutable) Revert() error {
+	if m.target == nil {
+		return errNoTarget
+	}
+	if err := restoreState(reflect.ValueOf(m.target).Elem(), reflect.ValueOf(m.originalState)); err != nil {
+		return err
+	}
+	m.updateState(ChangedFields{})
+	m.analyzed = true
+	return nil
+}
+
+// RevertField restores a field of a target object to its original value keeping other changes.
+// Field is looked up by its path (see SetValue), nested mutable objects within a field are reset with restored values.
+// Removed map elements are added back, map and slice elements missing within an original state are removed.
+// A path missing within an original state is reverted at its deepest existing level (eg. a nil pointer allocated by SetValue)
+func (m *Mutable) RevertField(fieldName string) error {
+	if m.target == nil {
+		return errNoTarget
+	}
+	target := reflect.ValueOf(m.target).Elem()
+	original := m.original()
+	tokens, ok := m.paths.splitExisting(target, fieldName)
+	if !ok {
+		if tokens, ok = m.paths.splitExisting(original, fieldName); !ok {
+			return errCannotFind(fieldName)
+		}
+	}
+	// Revert the deepest level of a path which exists within an original state or has been added to it,
+	// eg. a nil pointer allocated by SetValue is reverted as a whole
+	var err error
+	for level := len(tokens); level > 0; level-- {
+		var value reflect.Value
+		if value, err = lookupPath(original, tokens[:level]); err == nil {
+			err = walkPath(target, tokens[:level], restorePathValue(value))
+			break
+		}
+		if isAddedElem(original, tokens[:level]) {
+			err = walkPath(target, tokens[:level], removePathValue)
+			break
+		}
+	}
+	if err != nil {
+		return errCannotSetPath(fieldName, err)
+	}
+	// Invalidate analyzed changes of a target object and nested objects along a path
+	m.analyzed = false
+	invalidatePath(target, tokens)
+	return nil
+}
+
+// isAddedElem reports whether path tokens point to a missing element of an existing map or slice within original
+func isAddedElem(original reflect.Value, tokens []string) bool {
+	if len(tokens) < 2 {
+		return false
+	}
+	container, err := lookupPath(original, tokens[:len(tokens)-1])
+	if err != nil {
+		return false
+	}
+	for container.Kind() == reflect.Ptr || container.Kind() == reflect.Interface {
+		container = container.Elem()
+	}
+	return container.Kind() == reflect.Map || container.Kind() == reflect.Slice
+}
+
+// original returns an addressable value of an original state
+func (m *Mutable) original() reflect.Value {
+	original := reflect.New(reflect.TypeOf(m.originalState)).Elem()
+	original.Set(reflect.ValueOf(m.originalState))
+	return original
+}
+
+// restorePathValue returns an operation which sets a deep copy of src to a container element.
+// Map elements may be added, nested mutable objects of a restored element are reset
+func restorePathValue(src reflect.Value) pathOp {
+	return func(container reflect.Value, token string) error {
+		if err := setPathValue(copiedValue(src), container.Kind() == reflect.Map)(container, token); err != nil {
+			return err
+		}
+		value, err := getPathValue(container, token)
+		if err != nil {
+			return err
+		}
+		if value.Kind() == reflect.Struct && !value.CanAddr() {
+			// Not addressable elements can't keep their own mutable state
+			return nil
+		}
+		return resetNestedMutable(value)
+	}
+}
@@ -0,0 +1,130 @@
+package mutable
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type TestF struct {
+	Mutable
+	FieldA string `json:"field/a"`
+	FieldB TestB  `json:"field" mutable:"deep"`
+	FieldC *TestC `json:"field_c"`
+}
+
+func TestMutable_Revert(t *testing.T) {
+	var tst = &TestA{
+		FieldA: "one",
+		FieldE: &TestB{FieldA: "a"},
+		FieldG: []*TestC{{FieldA: "a"}},
+		FieldH: map[string]*TestC{"x": {FieldA: "x"}},
+	}
+	tst.ResetMutableState(tst)
+	tst.FieldA = "two"
+	tst.FieldE.FieldA = "b"
+	tst.FieldG[0].FieldA = "b"
+	tst.FieldG = append(tst.FieldG, &TestC{})
+	delete(tst.FieldH, "x")
+	assert.Len(t, tst.AnalyzeChanges(), 4)
+
+	if assert.NoError(t, tst.Revert()) {
+		assert.Empty(t, tst.AnalyzeChanges())
+		assert.Empty(t, tst.AnalyzeChangesForce())
+		assert.Equal(t, "one", tst.FieldA)
+		assert.Equal(t, "a", tst.FieldE.FieldA)
+		assert.Len(t, tst.FieldG, 1)
+		assert.Equal(t, "a", tst.FieldG[0].FieldA)
+		assert.Equal(t, "x", tst.FieldH["x"].FieldA)
+		// Mutable state of a target and nested objects is kept
+		assert.Equal(t, NotChanged, tst.MutableStatus)
+		assert.NoError(t, tst.SetValue("field_a", "three"))
+		assert.NoError(t, tst.FieldG[0].SetValue("field_a", "c"))
+		assert.Equal(t, "c", tst.FieldG[0].FieldA)
+	}
+
+	// Not initialized object
+	assert.Error(t, (&TestA{}).Revert())
+}
+
+func TestMutable_RevertField(t *testing.T) {
+	var tst = &TestF{
+		FieldA: "one",
+		FieldB: TestB{FieldA: "a", FieldB: []int{1}},
+		FieldC: &TestC{FieldA: "c"},
+	}
+	tst.ResetMutableState(tst)
+	tst.FieldA = "two"
+	tst.FieldB.FieldA = "b"
+	tst.FieldB.FieldB[0] = 2
+	tst.FieldC = &TestC{FieldA: "d"}
+	assert.Len(t, tst.AnalyzeChanges(), 3)
+
+	// Field names may contain LevelSeparator
+	assert.NoError(t, tst.RevertField("field/a"))
+	assert.Equal(t, "one", tst.FieldA)
+	assert.NoError(t, tst.RevertField("field/field_b"))
+	assert.Equal(t, []int{1}, tst.FieldB.FieldB)
+	changes := tst.AnalyzeChanges()
+	assert.Len(t, changes, 2)
+	assert.Len(t, changes["FieldB"].NestedFields, 1)
+	assert.Equal(t, "b", tst.FieldB.FieldA)
+
+	// Reverted nested mutable objects are reset
+	assert.NoError(t, tst.RevertField("field_c"))
+	assert.Equal(t, "c", tst.FieldC.FieldA)
+	assert.NoError(t, tst.FieldC.SetValue("field_a", "e"))
+	tst.RevertField("field_c/field_a")
+	assert.Equal(t, "c", tst.FieldC.FieldA)
+
+	// Original values are not affected by reverted ones
+	tst.FieldB.FieldB[0] = 3
+	tst.FieldC.FieldA = "f"
+	assert.NoError(t, tst.RevertField("field"))
+	assert.NoError(t, tst.RevertField("field_c"))
+	assert.Empty(t, tst.AnalyzeChanges())
+
+	assert.Error(t, tst.RevertField("unknown"))
+	assert.Error(t, tst.RevertField("field/unknown"))
+	assert.Error(t, (&TestF{}).RevertField("field_c"))
+}
+
+func TestMutable_RevertField_collectionElements(t *testing.T) {
+	var tst = &TestA{
+		FieldH: map[string]*TestC{"x": {FieldA: "x"}},
+		FieldK: []int{1},
+	}
+	assert.NoError(t, tst.ResetMutableState(tst))
+	delete(tst.FieldH, "x")
+	tst.FieldH["y"] = &TestC{FieldA: "y"}
+	tst.FieldK = append(tst.FieldK, 2, 3)
+
+	// Removed elements are added back
+	assert.NoError(t, tst.RevertField("field_h/x"))
+	if assert.NotNil(t, tst.FieldH["x"]) {
+		assert.Equal(t, "x", tst.FieldH["x"].FieldA)
+	}
+	// Added elements are removed
+	assert.NoError(t, tst.RevertField("field_h/y"))
+	assert.NotContains(t, tst.FieldH, "y")
+	assert.NoError(t, tst.RevertField("field_k/2"))
+	assert.NoError(t, tst.RevertField("field_k/1"))
+	assert.Equal(t, []int{1}, tst.FieldK)
+	assert.Empty(t, tst.AnalyzeChanges())
+
+	assert.Error(t, tst.RevertField("field_h/z"))
+}
+
+func TestMutable_RevertField_allocated(t *testing.T) {
+	var tst = &TestA{FieldH: map[string]*TestC{}}
+	assert.NoError(t, tst.ResetMutableState(tst))
+	assert.NoError(t, tst.SetValue("field_e/field_a", "x"))
+	assert.NoError(t, tst.SetValue("field_h/y/field_a", "y"))
+
+	// Levels allocated by SetValue are reverted as a whole
+	assert.NoError(t, tst.RevertField("field_e/field_a"))
+	assert.Nil(t, tst.FieldE)
+	assert.NoError(t, tst.RevertField("field_h/y/field_a"))
+	assert.NotContains(t, tst.FieldH, "y")
+	assert.Empty(t, tst.AnalyzeChanges())
+}
@@ -0,0 +1,77 @@
+package mutable
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// FieldValue is a value of a field with a given path
+type FieldValue struct {
+	Path  string      // Path of a field (see SetValue)
+	Value interface{} // Value of a field
+}
+
+// FieldError is an error of setting a value to a field with a given path
+type FieldError struct {
+	Path string // Path of a field
+	Err  error  // Error of a field
+}
+
+// FieldErrors are errors of every failed field returned by SetValues
+type FieldErrors []FieldError
+
+// Error implements error interface for FieldErrors
+func (e FieldErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, fieldError := range e {
+		messages[i] = fieldError.Err.Error()
+	}
+	return "cannot set values: " + strings.Join(messages, "; ")
+}
+
+// SetValues sets values for given fields by their paths (see SetValue) in order of paths.
+// Values are set only if all of them can be set, otherwise an object is left untouched and FieldErrors
+// of every failed field are returned. Changes are recorded to history as a single entry
+func (m *Mutable) SetValues(values map[string]interface{}) error {
+	ordered := make([]FieldValue, 0, len(values))
+	for path, value := range values {
+		ordered = append(ordered, FieldValue{Path: path, Value: value})
+	}
+	sort.Slice(ordered, func(i, j int) bool {
+		return ordered[i].Path < ordered[j].Path
+	})
+	return m.SetValuesOrdered(ordered)
+}
+
+// SetValuesOrdered sets values for given fields by their paths (see SetValue) in a given order.
+// Values are set only if all of them can be set (see SetValues)
+func (m *Mutable) SetValuesOrdered(values []FieldValue) error {
+	if m.target == nil {
+		return errNoTarget
+	}
+	// Try values on a copy of a target object first
+	target := reflect.ValueOf(m.target).Elem()
+	dryRun := reflect.New(target.Type()).Elem()
+	dryRun.Set(deepCopy(target))
+	var errs FieldErrors
+	for _, fieldValue := range values {
+		if err := trySetValueToObject(dryRun, fieldValue.Path, fieldValue.Value, m.paths); err != nil {
+			errs = append(errs, FieldError{Path: fieldValue.Path, Err: err})
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	m.BeginGroup()
+	defer m.EndGroup()
+	for _, fieldValue := range values {
+		if err := m.SetValue(fieldValue.Path, fieldValue.Value); err != nil {
+			errs = append(errs, FieldError{Path: fieldValue.Path, Err: err})
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
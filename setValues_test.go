@@ -0,0 +1,57 @@
+package mutable
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMutable_SetValues(t *testing.T) {
+	var tst = &TestA{FieldK: []int{1}}
+	assert.Equal(t, errNoTarget, tst.SetValues(nil))
+	assert.NoError(t, tst.ResetMutableState(tst))
+	tst.EnableHistory(0)
+
+	assert.NoError(t, tst.SetValues(map[string]interface{}{
+		"field_a":           "one",
+		"field_b":           "1.5",
+		"field_e/field_a":   "two",
+		"field_h/x/field_a": "three",
+	}))
+	assert.Equal(t, "one", tst.FieldA)
+	assert.Equal(t, 1.5, tst.FieldB)
+	assert.Equal(t, "two", tst.FieldE.FieldA)
+	assert.Equal(t, "three", tst.FieldH["x"].FieldA)
+	assert.Len(t, tst.History(), 1)
+
+	// Nothing is set if any value fails
+	err := tst.SetValues(map[string]interface{}{
+		"field_a":   "four",
+		"field_b":   "x",
+		"field_k/0": 2,
+		"unknown":   1,
+	})
+	var fieldErrors FieldErrors
+	if assert.True(t, errors.As(err, &fieldErrors)) && assert.Len(t, fieldErrors, 2) {
+		assert.Equal(t, "field_b", fieldErrors[0].Path)
+		assert.True(t, IsCannotSetErr(fieldErrors[0].Err))
+		assert.Equal(t, "unknown", fieldErrors[1].Path)
+		assert.True(t, IsCannotFindErr(fieldErrors[1].Err))
+		assert.Contains(t, err.Error(), "field_b")
+		assert.Contains(t, err.Error(), "unknown")
+	}
+	assert.Equal(t, "one", tst.FieldA)
+	assert.Equal(t, []int{1}, tst.FieldK)
+	assert.Len(t, tst.History(), 1)
+
+	// Values are set in a given order
+	assert.NoError(t, tst.SetValuesOrdered([]FieldValue{
+		{Path: "field_k/1", Value: 2},
+		{Path: "field_k/2", Value: 3},
+		{Path: "field_k/0", Value: 0},
+	}))
+	assert.Equal(t, []int{0, 2, 3}, tst.FieldK)
+	assert.NoError(t, tst.Undo())
+	assert.Equal(t, []int{1}, tst.FieldK)
+}
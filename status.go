@@ -13,6 +13,7 @@ const (
 	Removed
 	Added
 	Changed
+	Moved
 )
 
 // String implements Stringer interface for Status
@@ -26,6 +27,8 @@ func (m Status) String() string {
 		return "Added"
 	case Changed:
 		return "Changed"
+	case Moved:
+		return "Moved"
 	default:
 		return strconv.Itoa(int(m))
 	}
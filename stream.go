@@ -0,0 +1,144 @@
+package mutable
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ChangeEvent is an event of a field change delivered by Subscribe
+type ChangeEvent struct {
+	Path     string      `json:"path"`      // Path of a changed field (see OnChange)
+	OldValue interface{} `json:"old_value"` // Old value
+	NewValue interface{} `json:"new_value"` // New value
+	Time     time.Time   `json:"time"`      // Time of a change (of the latest one for coalesced events)
+	Seq      uint64      `json:"seq"`       // Sequence number of a change within an object (of the latest one for coalesced events)
+}
+
+// SubscribeOptions contains options of a change events stream
+type SubscribeOptions struct {
+	Pattern  string        // Glob pattern of field paths (see OnChange), all fields if it's empty
+	Debounce time.Duration // Events are delivered when no changes happen during Debounce (disabled if it's zero)
+	Coalesce bool          // Undelivered events of the same field are coalesced into one (always enabled with Debounce)
+	Buffer   int           // Buffer size of a channel
+}
+
+// Subscribe returns a channel of change events of fields set with SetValue or detected by AnalyzeChanges
+// (see OnChange). Coalesced event has an old value of the first change and a new value of the latest one.
+// Changes never wait for receivers, undelivered events are queued. A channel is closed when ctx is done
+func (m *Mutable) Subscribe(ctx context.Context, opts SubscribeOptions) <-chan ChangeEvent {
+	if len(opts.Pattern) == 0 {
+		opts.Pattern = "**"
+	}
+	s := &subscription{
+		opts:   opts,
+		signal: make(chan struct{}, 1),
+		events: make(chan ChangeEvent, opts.Buffer),
+	}
+	unsubscribe := m.observe(opts.Pattern, func(c ChangedField, seq uint64, t time.Time) {
+		s.push(ChangeEvent{
+			Path:     c.Name,
+			OldValue: c.OldValue,
+			NewValue: c.NewValue,
+			Time:     t,
+			Seq:      seq,
+		})
+	})
+	go func() {
+		defer close(s.events)
+		defer unsubscribe()
+		s.run(ctx)
+	}()
+	return s.events
+}
+
+// subscription queues change events and delivers them to a channel
+type subscription struct {
+	opts    SubscribeOptions
+	mu      sync.Mutex
+	pending []ChangeEvent // Undelivered events
+	signal  chan struct{} // Signal of new pending events
+	events  chan ChangeEvent
+}
+
+// push queues an event coalescing it with an undelivered event of the same field if it's enabled
+func (s *subscription) push(event ChangeEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.opts.Coalesce || s.opts.Debounce > 0 {
+		for i := range s.pending {
+			if s.pending[i].Path == event.Path {
+				event.OldValue = s.pending[i].OldValue
+				s.pending[i] = event
+				s.wake()
+				return
+			}
+		}
+	}
+	s.pending = append(s.pending, event)
+	s.wake()
+}
+
+// wake signals about new pending events without blocking
+func (s *subscription) wake() {
+	select {
+	case s.signal <- struct{}{}:
+	default:
+	}
+}
+
+// run delivers pending events until ctx is done
+func (s *subscription) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.signal:
+		}
+		if s.opts.Debounce > 0 && !s.debounce(ctx) {
+			return
+		}
+		for {
+			event, ok := s.next()
+			if !ok {
+				break
+			}
+			select {
+			case s.events <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// debounce waits until no events are pushed during Debounce. It reports false if ctx is done
+func (s *subscription) debounce(ctx context.Context) bool {
+	timer := time.NewTimer(s.opts.Debounce)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-s.signal:
+			if !timer.Stop() {
+				<-timer.C
+			}
+			timer.Reset(s.opts.Debounce)
+		case <-timer.C:
+			return true
+		}
+	}
+}
+
+// next pops the first pending event. It reports false if there are no pending events
+func (s *subscription) next() (ChangeEvent, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.pending) == 0 {
+		return ChangeEvent{}, false
+	}
+	event := s.pending[0]
+	s.pending = s.pending[1:]
+	return event, true
+}
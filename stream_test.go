@@ -0,0 +1,88 @@
+package mutable
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMutable_Subscribe(t *testing.T) {
+	var tst = &TestE{FieldA: "one"}
+	tst.ResetMutableState(tst)
+	ctx, cancel := context.WithCancel(context.Background())
+	all := tst.Subscribe(ctx, SubscribeOptions{})
+	fieldA := tst.Subscribe(ctx, SubscribeOptions{Pattern: "field_a", Coalesce: true})
+
+	// Changes don't wait for receivers
+	assert.NoError(t, tst.SetValue("field_a", "two"))
+	assert.NoError(t, tst.SetValue("field/c/field_a", "c"))
+	assert.NoError(t, tst.SetValue("field_a", "three"))
+
+	event := <-all
+	assert.Equal(t, "field_a", event.Path)
+	assert.Equal(t, "one", event.OldValue)
+	assert.Equal(t, "two", event.NewValue)
+	assert.Equal(t, uint64(1), event.Seq)
+	assert.False(t, event.Time.IsZero())
+	event = <-all
+	assert.Equal(t, "field/c/field_a", event.Path)
+	event = <-all
+	assert.Equal(t, "three", event.NewValue)
+	assert.Equal(t, uint64(3), event.Seq)
+
+	// The first event may be delivered before coalescing
+	event = <-fieldA
+	assert.Equal(t, "one", event.OldValue)
+	if event.NewValue == "two" {
+		event = <-fieldA
+	}
+	assert.Equal(t, "three", event.NewValue)
+
+	cancel()
+	_, ok := <-all
+	assert.False(t, ok)
+	_, ok = <-fieldA
+	assert.False(t, ok)
+}
+
+func TestMutable_Subscribe_debounce(t *testing.T) {
+	var tst = &TestE{FieldA: "one"}
+	tst.ResetMutableState(tst)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := tst.Subscribe(ctx, SubscribeOptions{Debounce: 20 * time.Millisecond})
+
+	for _, value := range []string{"two", "three", "four"} {
+		assert.NoError(t, tst.SetValue("field_a", value))
+	}
+	assert.NoError(t, tst.SetValue("field/c/field_a", "c"))
+	select {
+	case event := <-events:
+		assert.Equal(t, "field_a", event.Path)
+		assert.Equal(t, "one", event.OldValue)
+		assert.Equal(t, "four", event.NewValue)
+		assert.Equal(t, uint64(3), event.Seq)
+	case <-time.After(time.Second):
+		t.Fatal("event is not delivered")
+	}
+	event := <-events
+	assert.Equal(t, "field/c/field_a", event.Path)
+
+	// Analyzed changes
+	tst.FieldB = &TestB{}
+	tst.AnalyzeChangesForce()
+	// Changes set with SetValue are not delivered again
+	select {
+	case event := <-events:
+		assert.Equal(t, "field_b", event.Path)
+	case <-time.After(time.Second):
+		t.Fatal("event is not delivered")
+	}
+	select {
+	case event := <-events:
+		t.Fatalf("unexpected event %v", event.Path)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -0,0 +1,32 @@
+package mutable
+
+import (
+	"reflect"
+	"strings"
+)
+
+// tagOptions contains parsed values of a mutable tag
+type tagOptions struct {
+	ignored bool   // Field changes are not tracked
+	deep    bool   // Field is analyzed deeply
+	key     string // Name of a key field of slice elements
+}
+
+// parseTagOptions returns options parsed from a mutable tag value of a field.
+// Tag value is a comma separated list of options (eg. `mutable:"deep"`, `mutable:"key=ID"`)
+func parseTagOptions(tag reflect.StructTag) tagOptions {
+	var opts tagOptions
+	tagValue, _ := tag.Lookup(mutTagName)
+	for _, option := range strings.Split(tagValue, ",") {
+		option = strings.TrimSpace(option)
+		switch {
+		case option == flagIgnore, option == flagIgnored:
+			opts.ignored = true
+		case option == flagDeepAnalyze:
+			opts.deep = true
+		case strings.HasPrefix(option, flagKey):
+			opts.key = strings.TrimPrefix(option, flagKey)
+		}
+	}
+	return opts
+}
@@ -0,0 +1,69 @@
+package mutable
+
+import (
+	"reflect"
+)
+
+// Tracker provides object changes tracking features for any struct type without embedding Mutable.
+// An original state of a tracked object is kept within a tracker, so a tracked object may be copied freely
+type Tracker[T any] struct {
+	target   *T          // Pointer to a tracked object
+	original T           // Original state of a tracked object (deep copy)
+	paths    pathOptions // Options of path based methods
+}
+
+// Track returns a tracker of target object changes. T must be a struct type
+func Track[T any](target *T) *Tracker[T] {
+	t := &Tracker[T]{target: target}
+	t.Reset()
+	return t
+}
+
+// Target returns a pointer to a tracked object
+func (t *Tracker[T]) Target() *T {
+	return t.target
+}
+
+// Original returns an original state of a tracked object
+func (t *Tracker[T]) Original() T {
+	return t.original
+}
+
+// Changes analyzes changes of a tracked object and returns changed fields data
+func (t *Tracker[T]) Changes() ChangedFields {
+	current := reflect.ValueOf(t.target).Elem()
+	if current.Kind() != reflect.Struct {
+		return ChangedFields{}
+	}
+	return tryAnalyzeChanges(current, reflect.ValueOf(&t.original).Elem())
+}
+
+// Set sets a value for given field by its name (see Mutable.SetValue for supported names and values)
+func (t *Tracker[T]) Set(fieldName string, value interface{}) error {
+	current := reflect.ValueOf(t.target).Elem()
+	if current.Kind() != reflect.Struct {
+		return errNotStruct
+	}
+	return trySetValueToObject(current, fieldName, value, t.paths)
+}
+
+// SetStrictPaths makes Set fail on nil pointers, nil maps and missing elements along a path
+// instead of allocating them
+func (t *Tracker[T]) SetStrictPaths(strict bool) {
+	t.paths.strict = strict
+}
+
+// SetPathSyntax sets a syntax of paths used by Set (LevelSeparator separated paths if syntax is nil)
+func (t *Tracker[T]) SetPathSyntax(syntax PathSyntax) {
+	t.paths.syntax = syntax
+}
+
+// Reset updates an original state with a current state of a tracked object
+func (t *Tracker[T]) Reset() {
+	reflect.ValueOf(&t.original).Elem().Set(deepCopy(reflect.ValueOf(t.target).Elem()))
+}
+
+// Revert restores a tracked object to its original state
+func (t *Tracker[T]) Revert() error {
+	return restoreState(reflect.ValueOf(t.target).Elem(), reflect.ValueOf(&t.original).Elem())
+}
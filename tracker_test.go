@@ -0,0 +1,81 @@
+package mutable
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type TestTracked struct {
+	FieldA string            `json:"field_a"`
+	FieldB []int             `json:"field_b"`
+	FieldC TestB             `json:"field_c" mutable:"deep"`
+	FieldD map[string]*TestC `json:"field_d"`
+}
+
+func TestTracker(t *testing.T) {
+	var tst = TestTracked{
+		FieldA: "one",
+		FieldB: []int{1, 2},
+		FieldD: map[string]*TestC{"one": {FieldA: "a"}},
+	}
+	tracker := Track(&tst)
+	assert.Equal(t, &tst, tracker.Target())
+	assert.Empty(t, tracker.Changes())
+
+	// Set values and change the object directly
+	assert.NoError(t, tracker.Set("field_a", "two"))
+	assert.NoError(t, tracker.Set("field_c/field_a", "nested"))
+	assert.Error(t, tracker.Set("unknown", 1))
+	tst.FieldB[0] = 0
+	tst.FieldD["one"].FieldA = "b"
+	changes := tracker.Changes()
+	assert.Len(t, changes, 4)
+	assert.Equal(t, "two", changes["FieldA"].NewValue)
+	assert.Equal(t, "nested", changes["FieldC"].NestedFields["FieldA"].NewValue)
+	assert.Equal(t, []int{0, 2}, changes["FieldB"].NewValue)
+	assert.Equal(t, "a", tracker.Original().FieldD["one"].FieldA)
+
+	// Copies of a tracked object don't affect the tracker
+	cp := tst
+	cp.FieldA = "three"
+	assert.Equal(t, "two", tracker.Changes()["FieldA"].NewValue)
+
+	// Revert
+	assert.NoError(t, tracker.Revert())
+	assert.Empty(t, tracker.Changes())
+	assert.Equal(t, "one", tst.FieldA)
+	assert.Equal(t, []int{1, 2}, tst.FieldB)
+	assert.Equal(t, "a", tst.FieldD["one"].FieldA)
+	// Reverted nested mutable objects are reset
+	assert.NoError(t, tst.FieldD["one"].SetValue("field_a", "c"))
+
+	// Reset
+	tracker.Reset()
+	assert.Empty(t, tracker.Changes())
+	assert.Equal(t, "c", tracker.Original().FieldD["one"].FieldA)
+}
+
+func TestTracker_notStruct(t *testing.T) {
+	var v = 1
+	tracker := Track(&v)
+	v = 2
+	assert.Empty(t, tracker.Changes())
+	assert.Error(t, tracker.Set("field", 1))
+	assert.NoError(t, tracker.Revert())
+	assert.Equal(t, 1, v)
+}
+
+func TestTracker_SetStrictPaths(t *testing.T) {
+	var tst TestTracked
+	tracker := Track(&tst)
+	tracker.SetStrictPaths(true)
+	assert.Error(t, tracker.Set("field_d/one/field_a", "a"))
+	assert.Nil(t, tst.FieldD)
+
+	tracker.SetStrictPaths(false)
+	assert.NoError(t, tracker.Set("field_d/one/field_a", "a"))
+	if assert.NotNil(t, tst.FieldD["one"]) {
+		assert.Equal(t, "a", tst.FieldD["one"].FieldA)
+	}
+}
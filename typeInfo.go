@@ -0,0 +1,107 @@
+package mutable
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// fieldInfo contains resolved metadata of a struct field
+type fieldInfo struct {
+	index     int        // Index of a field within a struct
+	name      string     // Real (as it stated in struct) field name
+	jsonName  string     // JSON name of a field (a real name if a field has no JSON name)
+	options   tagOptions // Parsed mutable tag options
+	isMutable bool       // Field is an embedded Mutable object
+}
+
+// typeInfo contains resolved metadata of a struct type
+type typeInfo struct {
+	fields       []fieldInfo           // Fields metadata in order of struct fields
+	byJSONName   map[string]*fieldInfo // Fields by their JSON names (used as path levels)
+	byName       map[string]*fieldInfo // Fields by their real names
+	mutableIndex []int                 // Index sequence of a Mutable field (nil if a type is not mutable)
+}
+
+// typeInfoCache is a cache of typeInfo objects by struct types
+var typeInfoCache sync.Map
+
+// getTypeInfo returns metadata of a struct type t
+func getTypeInfo(t reflect.Type) *typeInfo {
+	if info, ok := typeInfoCache.Load(t); ok {
+		return info.(*typeInfo)
+	}
+	info, _ := typeInfoCache.LoadOrStore(t, newTypeInfo(t))
+	return info.(*typeInfo)
+}
+
+// newTypeInfo resolves metadata of a struct type t
+func newTypeInfo(t reflect.Type) *typeInfo {
+	info := &typeInfo{
+		fields:     make([]fieldInfo, t.NumField()),
+		byJSONName: make(map[string]*fieldInfo, t.NumField()),
+		byName:     make(map[string]*fieldInfo, t.NumField()),
+	}
+	for z := 0; z < t.NumField(); z++ {
+		meta := t.Field(z)
+		field := &info.fields[z]
+		field.index = z
+		field.name = meta.Name
+		field.jsonName = jsonName(meta)
+		field.options = parseTagOptions(meta.Tag)
+		field.isMutable = meta.Type == mutableType
+		if field.isMutable {
+			// Mutable itself can't be a destination field
+			continue
+		}
+		info.byJSONName[field.jsonName] = field
+		info.byName[field.name] = field
+	}
+	// Get a Mutable field, including promoted ones
+	if mf, ok := t.FieldByName(mutFieldName); ok && mf.Type == mutableType && !hasPtrEmbedded(t, mf.Index) {
+		info.mutableIndex = mf.Index
+	}
+	return info
+}
+
+// hasPtrEmbedded reports whether a field with index sequence is promoted through an embedded pointer
+func hasPtrEmbedded(t reflect.Type, index []int) bool {
+	for _, i := range index[:len(index)-1] {
+		if t = t.Field(i).Type; t.Kind() == reflect.Ptr {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonName returns a JSON name of a struct field or its real name if a field has no JSON name
+func jsonName(field reflect.StructField) string {
+	tagValue, ok := field.Tag.Lookup("json")
+	if !ok || tagValue == "-" {
+		return field.Name
+	}
+	if name := strings.Split(tagValue, ",")[0]; len(name) > 0 {
+		return name
+	}
+	return field.Name
+}
+
+// fieldByName returns a field metadata by its JSON or real name
+func (t *typeInfo) fieldByName(name string) *fieldInfo {
+	if field, ok := t.byJSONName[name]; ok {
+		return field
+	}
+	return t.byName[name]
+}
+
+// mutableField returns a Mutable field of a struct value v or an invalid value if v is not a mutable object
+func mutableField(v reflect.Value) reflect.Value {
+	if v.Kind() != reflect.Struct {
+		return reflect.Value{}
+	}
+	info := getTypeInfo(v.Type())
+	if info.mutableIndex == nil {
+		return reflect.Value{}
+	}
+	return v.FieldByIndex(info.mutableIndex)
+}
@@ -0,0 +1,32 @@
+package mutable
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetTypeInfo(t *testing.T) {
+	type testBase struct {
+		Mutable
+	}
+	var tst = struct {
+		testBase
+		FieldA string `json:"field_a,omitempty"`
+		FieldB string `json:"-"`
+		FieldC string `json:",omitempty" mutable:"deep,key=ID"`
+		FieldD string `json:"field/d"`
+	}{}
+	info := getTypeInfo(reflect.TypeOf(tst))
+	assert.True(t, info == getTypeInfo(reflect.TypeOf(tst)), "cached")
+	assert.Equal(t, []int{0, 0}, info.mutableIndex, "promoted mutable")
+	assert.Equal(t, "field_a", info.fields[1].jsonName)
+	assert.Equal(t, "FieldB", info.fields[2].jsonName)
+	assert.Equal(t, "FieldC", info.fields[3].jsonName)
+	assert.Equal(t, tagOptions{deep: true, key: "ID"}, info.fields[3].options)
+	assert.Equal(t, 4, info.fieldByName("field/d").index)
+	assert.Equal(t, 1, info.fieldByName("FieldA").index)
+	assert.Nil(t, info.fieldByName("field_b"))
+	assert.True(t, isMutable(reflect.ValueOf(tst)), "mutable")
+}